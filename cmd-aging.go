@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/report"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// printAging prints the aging of the accounts receivable and payable
+// at the given date (YYYY-MM-DD), or today if empty.
+func printAging(book *model.Book, date string) error {
+	asOf := time.Now()
+	if date != "" {
+		var err error
+		if asOf, err = time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("Invalid date %q: %s", date, err)
+		}
+	}
+
+	for _, x := range []struct {
+		title   string
+		accType types.AccountType
+	}{
+		{"ACCOUNTS RECEIVABLE", types.AccountTypeReceivable},
+		{"ACCOUNTS PAYABLE", types.AccountTypePayable},
+	} {
+		rpt := report.NewAgingReport(book, x.accType, asOf)
+
+		fmt.Printf("*** %s AGING at %s ***\n", x.title, rpt.Date.Format("2006-01-02"))
+		fmt.Print(StringPad("", 30, " "))
+		for _, label := range report.BucketLabels {
+			fmt.Printf(" %12s", label)
+		}
+		fmt.Printf(" %12s\n", "Total")
+
+		printRow := func(name string, buckets *[report.NumBuckets]types.Numeric, total *types.Numeric) {
+			fmt.Print(StringPad(name, 30, " "))
+			for j := range buckets {
+				fmt.Printf(" %12.2f", buckets[j].Float64())
+			}
+			fmt.Printf(" %12.2f\n", total.Float64())
+		}
+		for _, row := range rpt.Rows {
+			printRow(row.Owner.Name(), &row.Buckets, &row.Total)
+		}
+		printRow("TOTAL", &rpt.Buckets, &rpt.Total)
+		fmt.Println("")
+	}
+	return nil
+}
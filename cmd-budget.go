@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/report"
+)
+
+// printBudgets prints the budget-vs-actual report of every budget.
+func printBudgets(book *model.Book) {
+	for _, bgt := range book.Budgets {
+		fmt.Printf("*** BUDGET %s ***\n", bgt.Name)
+		if bgt.Description != "" {
+			fmt.Println(bgt.Description)
+		}
+		rpt := report.NewBudgetReport(book, bgt)
+		for j, p := range rpt.Periods {
+			fmt.Printf("\nPeriod %d: %s - %s\n", j+1,
+				p.Start.Format("2006-01-02"), p.End.AddDate(0, 0, -1).Format("2006-01-02"))
+			fmt.Printf("%s %12s %12s %12s\n", StringPad("Account", 40, " "), "Budgeted", "Actual", "Difference")
+			for _, r := range p.Rows {
+				name := strings.Repeat("  ", r.Depth) + r.Account.Name
+				fmt.Printf("%s %12.2f %12.2f %12.2f\n", StringPad(name, 40, " "),
+					r.Budgeted.Float64(), r.Actual.Float64(), r.Difference.Float64())
+			}
+		}
+		fmt.Println("")
+	}
+}
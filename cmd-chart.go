@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mmbros/gnucash-viewer/model"
+)
+
+// printAccounts prints the account tree of the book,
+// or of the chart of an accounts-only file.
+func printAccounts(gnc *model.Gnc) {
+	if gnc.Book != nil {
+		gnc.Book.Accounts.PrintTree("  ")
+		return
+	}
+	chart := gnc.Chart
+	if chart.Title != "" {
+		fmt.Printf("*** %s ***\n", chart.Title)
+		if chart.ShortDescription != "" {
+			fmt.Println(chart.ShortDescription)
+		}
+		fmt.Println("")
+	}
+	chart.Accounts.PrintTree("  ")
+}
+
+// compareChart prints the differences between the account chart of the
+// template file and the accounts of the book: the accounts of the chart
+// missing in the book (-), the accounts of the book missing in the chart (+),
+// and the accounts with a different type, commodity or placeholder flag (~).
+func compareChart(book *model.Book, path string) error {
+	if path == "" {
+		return fmt.Errorf("Missing template file")
+	}
+	gnc, err := model.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	accounts, err := gnc.Accounts()
+	if err != nil {
+		return err
+	}
+
+	diffs := model.CompareAccounts(accounts, &book.Accounts)
+	for _, d := range diffs {
+		fmt.Println(d)
+	}
+	if len(diffs) == 0 {
+		fmt.Println("The accounts of the book match the chart.")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mmbros/gnucash-viewer/model"
+)
+
+// checkReport type is the JSON output of the check command.
+type checkReport struct {
+	File     string           `json:"file"`
+	Errors   int              `json:"errors"`
+	Warnings int              `json:"warnings"`
+	Problems []*model.Problem `json:"problems"`
+}
+
+// checkBook writes as JSON to stdout the problems of the book found by
+// Book.Check, with the warnings of the reading of the file. It returns
+// an error if some problem has error severity.
+func checkBook(gnc *model.Gnc, path string) error {
+	report := checkReport{File: path, Problems: []*model.Problem{}}
+	for _, w := range gnc.Warnings {
+		report.Problems = append(report.Problems, &model.Problem{
+			Check:    "read",
+			Severity: model.SeverityWarning,
+			Message:  w.Error(),
+		})
+	}
+	report.Problems = append(report.Problems, gnc.Book.Check()...)
+	for _, p := range report.Problems {
+		if p.Severity == model.SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(&report); err != nil {
+		return err
+	}
+	if report.Errors > 0 {
+		return fmt.Errorf("check failed: %d errors", report.Errors)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mmbros/gnucash-viewer/model"
+)
+
+// printContacts prints customers, vendors and employees,
+// with the jobs of customers and vendors.
+func printContacts(book *model.Book) {
+	biz := &book.Business
+
+	printJobs := func(jobs []*model.Job) {
+		for _, j := range jobs {
+			status := "active"
+			if !j.Active {
+				status = "closed"
+			}
+			fmt.Printf("      job %s %s (%s) %s\n", j.Number, j.Name, status, j.Reference)
+		}
+	}
+
+	fmt.Printf("*** CUSTOMERS (%d) ***\n", len(biz.Customers))
+	for _, c := range biz.Customers {
+		fmt.Printf("%s %s [%s]\n", c.Number, c.Name, c.Currency)
+		if lines := c.Addr.Lines(); len(lines) > 0 {
+			fmt.Printf("      %s\n", strings.Join(lines, ", "))
+		}
+		printJobs(c.Jobs)
+	}
+
+	fmt.Printf("\n*** VENDORS (%d) ***\n", len(biz.Vendors))
+	for _, v := range biz.Vendors {
+		fmt.Printf("%s %s [%s]\n", v.Number, v.Name, v.Currency)
+		if lines := v.Addr.Lines(); len(lines) > 0 {
+			fmt.Printf("      %s\n", strings.Join(lines, ", "))
+		}
+		printJobs(v.Jobs)
+	}
+
+	fmt.Printf("\n*** EMPLOYEES (%d) ***\n", len(biz.Employees))
+	for _, e := range biz.Employees {
+		fmt.Printf("%s %s (%s)\n", e.Number, e.Addr.Name, e.Username)
+	}
+}
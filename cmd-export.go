@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+)
+
+// exportTransactions writes the splits of the transactions of the file
+// as CSV to stdout. The file is read as a stream, so that books of any
+// size are exported in constant memory.
+//
+// The dates are YYYY-MM-DD, and empty values are not limits. If account
+// is not empty, only the transactions with a split in the account with
+// that name, or in one of its descendants, are exported.
+func exportTransactions(path, from, to, account string) error {
+	var (
+		filter model.TransactionFilter
+		err    error
+	)
+	if from != "" {
+		if filter.From, err = time.Parse("2006-01-02", from); err != nil {
+			return fmt.Errorf("Invalid date %q: %s", from, err)
+		}
+	}
+	if to != "" {
+		if filter.To, err = time.Parse("2006-01-02", to); err != nil {
+			return fmt.Errorf("Invalid date %q: %s", to, err)
+		}
+		// include the whole last day
+		filter.To = filter.To.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if account != "" {
+		filter.Account = func(acc *model.Account) bool {
+			for a := acc; a != nil; a = a.Parent {
+				if a.Name == account {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"date", "num", "description", "account", "memo", "value", "quantity"})
+	err = model.StreamTransactionsFile(path, &filter, func(t *model.Transaction) error {
+		for _, s := range t.Splits {
+			w.Write([]string{
+				time.Time(t.DatePosted).Format("2006-01-02"),
+				t.Num,
+				t.Description,
+				s.Account.FullName(),
+				s.Memo,
+				t.Currency.FormatAmount(&s.Value),
+				s.Account.Currency.FormatAmount(&s.Quantity),
+			})
+		}
+		return w.Error()
+	})
+	w.Flush()
+	if err != nil {
+		return err
+	}
+	return w.Error()
+}
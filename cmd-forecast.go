@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/report"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// printForecast prints the forecast of the bank and credit accounts,
+// flagging the first date each bank account goes under the threshold,
+// and each credit account goes over the credit limit, or the threshold
+// if the credit limit is empty.
+func printForecast(book *model.Book, months int, threshold, creditLimit string) error {
+	th, err := types.FromString(threshold)
+	if err != nil {
+		return fmt.Errorf("Invalid threshold %q: %s", threshold, err)
+	}
+	var limit *types.Numeric
+	if creditLimit != "" {
+		if limit, err = types.FromString(creditLimit); err != nil {
+			return fmt.Errorf("Invalid credit limit %q: %s", creditLimit, err)
+		}
+	}
+
+	f := report.NewForecast(book, time.Now(), months, *th, limit)
+	last := f.Date(f.Days - 1)
+
+	fmt.Printf("Forecast from %s to %s, threshold %.2f\n\n",
+		f.From.Format("2006-01-02"), last.Format("2006-01-02"), th.Float64())
+
+	for _, acc := range book.Accounts.List {
+		if !f.IsWatched(acc) {
+			continue
+		}
+		balances := f.Balances[acc]
+
+		// find the minimum balance
+		min := 0
+		for j := range balances {
+			if types.Sub(&balances[j], &balances[min]).Sign() < 0 {
+				min = j
+			}
+		}
+
+		flag := "-"
+		if d, ok := f.Crossings[acc]; ok {
+			flag = "UNDER THRESHOLD on " + d.Format("2006-01-02")
+			if acc.Type == types.AccountTypeCredit || acc.Type == types.AccountTypeCreditLine {
+				flag = "DEBT OVER THRESHOLD on " + d.Format("2006-01-02")
+				if limit != nil {
+					flag = "OVER CREDIT LIMIT on " + d.Format("2006-01-02")
+				}
+			}
+		}
+		fmt.Printf("%s %12.2f -> %12.2f (min %12.2f on %s) %s\n",
+			StringPad(acc.FullName(), 40, " "),
+			balances[0].Float64(),
+			balances[len(balances)-1].Float64(),
+			balances[min].Float64(),
+			f.Date(min).Format("2006-01-02"),
+			flag)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/report"
+)
+
+// printCapitalGains prints the realized capital gains grouped by tax year.
+func printCapitalGains(book *model.Book) {
+	for _, ty := range report.CapitalGains(book) {
+		fmt.Printf("*** TAX YEAR %d ***\n", ty.Year)
+		for _, g := range ty.Gains {
+			term := "short"
+			if g.LongTerm() {
+				term = "long"
+			}
+			fmt.Printf("%-30s %-20s %s %s %5d days (%-5s) cost=%12.2f proceeds=%12.2f gain=%12.2f\n",
+				StringPad(g.Account.FullName(), 30, " "),
+				StringPad(g.Lot.Title(), 20, " "),
+				g.Acquired.Format("2006-01-02"),
+				g.Disposed.Format("2006-01-02"),
+				int(g.HoldingPeriod().Hours()/24),
+				term,
+				g.CostBasis.Float64(),
+				g.Proceeds.Float64(),
+				g.Gain.Float64())
+		}
+		fmt.Printf("Total gain %d: %.2f\n\n", ty.Year, ty.Total.Float64())
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/render"
+	"github.com/mmbros/gnucash-viewer/report"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// renderInvoice writes the HTML of the invoice with the given number
+// (or GUID) to stdout.
+func renderInvoice(book *model.Book, number, templateDir string) error {
+	var inv *model.Invoice
+	for _, x := range book.Business.Invoices {
+		if x.Number == number || x.ID == types.GUID(number) {
+			inv = x
+			break
+		}
+	}
+	if inv == nil {
+		return fmt.Errorf("Invoice not found: %q", number)
+	}
+
+	r, err := render.New(templateDir)
+	if err != nil {
+		return err
+	}
+	return r.Invoice(os.Stdout, book, inv)
+}
+
+// renderStatement writes the HTML statement of the customer with the given
+// number (or GUID) to stdout. The dates are YYYY-MM-DD: an empty from means
+// the first day of the year of to, an empty to means today.
+func renderStatement(book *model.Book, number, from, to, templateDir string) error {
+	var cust *model.Customer
+	for _, x := range book.Business.Customers {
+		if x.Number == number || x.ID == types.GUID(number) {
+			cust = x
+			break
+		}
+	}
+	if cust == nil {
+		return fmt.Errorf("Customer not found: %q", number)
+	}
+
+	dateTo := time.Now()
+	if to != "" {
+		var err error
+		if dateTo, err = time.Parse("2006-01-02", to); err != nil {
+			return fmt.Errorf("Invalid date %q: %s", to, err)
+		}
+	}
+	dateFrom := time.Date(dateTo.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	if from != "" {
+		var err error
+		if dateFrom, err = time.Parse("2006-01-02", from); err != nil {
+			return fmt.Errorf("Invalid date %q: %s", from, err)
+		}
+	}
+
+	r, err := render.New(templateDir)
+	if err != nil {
+		return err
+	}
+	st := report.NewStatement(book, cust, dateFrom, dateTo)
+	return r.Statement(os.Stdout, book, st)
+}
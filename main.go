@@ -12,30 +12,154 @@ package main
 import (
 	"flag"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/mmbros/gnucash-viewer/model"
 	"github.com/mmbros/gnucash-viewer/query"
 )
 
-var gnucashPath = flag.String("gnucash-file", "data-crypt/mau.gnucash", "GnuCash file path")
+var (
+	gnucashPath = flag.String("gnucash-file", "data-crypt/mau.gnucash", "GnuCash file path, - to read from stdin")
+	months      = flag.Int("months", 3, "number of months of the forecast")
+	threshold   = flag.String("threshold", "0", "forecast balance threshold, as integer or num/den")
+	creditLimit = flag.String("credit-limit", "", "forecast debt limit of the credit accounts, as integer or num/den (default the threshold)")
+	date        = flag.String("date", "", "aging report date, as YYYY-MM-DD (default today)")
+	from        = flag.String("from", "", "statement and export first date, as YYYY-MM-DD (statement default first day of the year)")
+	to          = flag.String("to", "", "statement and export last date, as YYYY-MM-DD (statement default today)")
+	account     = flag.String("account", "", "name of the account of the exported transactions (default all)")
+	templateDir = flag.String("template-dir", "", "directory of the HTML templates overriding the default ones")
+	format      = flag.String("format", "", "format of the GnuCash file (default detected by the file signature)")
+	lenient     = flag.Bool("lenient", false, "skip the objects of the XML book with errors, printing them to stderr")
+)
 
 func main() {
-	defer timeTrack(time.Now(), "task duration:")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [command]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Commands:\n")
+		fmt.Fprintf(os.Stderr, "  view      print a summary of the book (default)\n")
+		fmt.Fprintf(os.Stderr, "  gains     print the realized capital gains by tax year\n")
+		fmt.Fprintf(os.Stderr, "  forecast  print the balance forecast of bank and credit accounts\n")
+		fmt.Fprintf(os.Stderr, "  budget    print the budget-vs-actual report of every budget\n")
+		fmt.Fprintf(os.Stderr, "  contacts  print customers, vendors, employees and jobs\n")
+		fmt.Fprintf(os.Stderr, "  aging     print the aging of accounts receivable and payable\n")
+		fmt.Fprintf(os.Stderr, "  invoice <number>\n")
+		fmt.Fprintf(os.Stderr, "            write the HTML of the invoice to stdout\n")
+		fmt.Fprintf(os.Stderr, "  statement <customer number>\n")
+		fmt.Fprintf(os.Stderr, "            write the HTML statement of the customer to stdout\n")
+		fmt.Fprintf(os.Stderr, "  accounts  print the account tree of the book or of the account template\n")
+		fmt.Fprintf(os.Stderr, "  chart <template file>\n")
+		fmt.Fprintf(os.Stderr, "            compare the accounts of the book with the account template\n")
+		fmt.Fprintf(os.Stderr, "  check     write the integrity problems of the book as JSON to stdout,\n")
+		fmt.Fprintf(os.Stderr, "            exiting with status 1 on errors\n")
+		fmt.Fprintf(os.Stderr, "  export    write the splits of the transactions as CSV to stdout,\n")
+		fmt.Fprintf(os.Stderr, "            reading the XML file as a stream\n")
+		fmt.Fprintf(os.Stderr, "  formats   print the supported file formats\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	// commands not loading the whole book
+	switch flag.Arg(0) {
+	case "formats":
+		printFormats()
+		return
+	case "export":
+		if err := exportTransactions(*gnucashPath, *from, *to, *account); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	gnc, err := model.ReadFile(*gnucashPath)
+	if flag.Arg(0) != "check" {
+		// the output of check is JSON only
+		defer timeTrack(time.Now(), "task duration:")
+	}
+
+	var gnc *model.Gnc
+	var err error
+	opts := &model.ReadOptions{Lenient: *lenient}
+	if *gnucashPath == "-" {
+		gnc, err = model.ReadWithOptions(os.Stdin, opts)
+	} else {
+		gnc, err = model.LoadFile(*gnucashPath, *format, opts)
+	}
 
 	if err != nil {
 		panic(err)
 	}
+	for _, w := range gnc.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", w)
+	}
+
+	cmd := flag.Arg(0)
+	if gnc.Book == nil && cmd != "accounts" {
+		fmt.Fprintf(os.Stderr, "No book in %s: an account template can only be printed by the accounts command\n", *gnucashPath)
+		os.Exit(1)
+	}
+
+	switch cmd {
+	case "accounts":
+		printAccounts(gnc)
+	case "chart":
+		err = compareChart(gnc.Book, flag.Arg(1))
+	case "check":
+		err = checkBook(gnc, *gnucashPath)
+	case "", "view":
+		view(gnc.Book)
+	case "gains":
+		printCapitalGains(gnc.Book)
+	case "forecast":
+		err = printForecast(gnc.Book, *months, *threshold, *creditLimit)
+	case "budget":
+		printBudgets(gnc.Book)
+	case "contacts":
+		printContacts(gnc.Book)
+	case "aging":
+		err = printAging(gnc.Book, *date)
+	case "invoice":
+		err = renderInvoice(gnc.Book, flag.Arg(1), *templateDir)
+	case "statement":
+		err = renderStatement(gnc.Book, flag.Arg(1), *from, *to, *templateDir)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", cmd)
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
 
-	book := gnc.Book
+// printFormats prints the formats of the registered backends
+// with their capabilities.
+func printFormats() {
+	for _, f := range model.Formats() {
+		fmt.Printf("%-8s %s\n", f, model.Lookup(f).Capabilities())
+	}
+}
 
+// view prints a summary of the book.
+func view(book *model.Book) {
 	fmt.Printf("Commodites   (1)   : %d\n", book.Commodities.Len())
 	fmt.Printf("Accounts     (161) : %d\n", book.Accounts.Len())
 	fmt.Printf("Transactions (2553): %d\n", book.Transactions.Len())
+	fmt.Printf("Prices             : %d\n", book.PriceDB.Len())
+	fmt.Printf("Scheduled          : %d\n", book.ScheduledTransactions.Len())
+	fmt.Printf("Invoices           : %d\n", len(book.Business.Invoices))
 	fmt.Println("")
 
+	if preserved := book.Preserved(); len(preserved) > 0 {
+		fmt.Println("Preserved but not understood:")
+		for _, k := range model.PreservedKeys(preserved) {
+			fmt.Printf("  %s: %d\n", k, preserved[k])
+		}
+		fmt.Println("")
+	}
+
 	for j, cmdty := range book.Commodities {
 		fmt.Printf("*** COMMODITY #%d ***\n", j)
 		fmt.Printf("ID: %s\n", cmdty.ID)
@@ -81,6 +205,10 @@ func main() {
 
 	// find account by path
 	accounts, err := query.FindAccounts(".//Benzina", book.Accounts.Root)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	for j, a := range accounts {
 		fmt.Printf("%d) %s\n", j+1, a.FullName())
 	}
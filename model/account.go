@@ -41,14 +41,20 @@ type Accounts struct {
 	Root *Account
 	//Map  map[types.GUID]*Account
 	List []*Account
+	// Orphans are the accounts not descending from Root, as the accounts
+	// with a missing parent. Their descendants are not orphans.
+	Orphans []*Account
 }
 
 // Account type
 type Account struct {
+	ID          types.GUID
 	Type        types.AccountType
 	Name        string
+	Code        string
 	Description string
 	Currency    *Commodity
+	Slots       Slots
 
 	// SCU = Smallest Commodity Unit, in most cases 100
 	CommodityScu   int
@@ -56,21 +62,31 @@ type Account struct {
 
 	Parent   *Account
 	Children []*Account
+	Lots     Lots
+
+	// ParentID is the GUID of the parent account, as read:
+	// it is kept for the orphan accounts, whose Parent is nil.
+	ParentID types.GUID
+
+	// elements and attributes not understood by the decoder
+	Unknown
 }
 
-// AccountMap
-type AccountMap map[string]*Account
+// AccountMap maps the GUID of an account to the account.
+type AccountMap map[types.GUID]*Account
 
-func AccountUnmarshalXML(decoder *xml.Decoder, commodities Commodities) (a *Account, ID string, ParentID string, err error) {
+// AccountUnmarshalXML reads an account element, returning the new account
+// and the GUID of its parent.
+func AccountUnmarshalXML(decoder *xml.Decoder, commodities Commodities) (a *Account, ParentID types.GUID, err error) {
 
 	acc := Account{}
 
 LOOP:
 	for {
 		// Read tokens from the XML document in a stream.
-		t, _ := decoder.Token()
-		if t == nil {
-			break LOOP
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, "", err
 		}
 		// Inspect the type of the token just read.
 		switch se := t.(type) {
@@ -80,16 +96,24 @@ LOOP:
 			switch se.Name.Local {
 			case "commodity":
 				var cmdty Commodity
-				if err = decoder.DecodeElement(&cmdty, &se); err != nil {
-					return
+				if err = decoder.DecodeElement(&cmdty, &se); err == nil {
+					acc.Currency = commodities.Lookup(&cmdty)
 				}
-				acc.Currency = commodities.Get(cmdty.Space, cmdty.ID)
 			case "name":
 				v = &acc.Name
+			case "code":
+				v = &acc.Code
 			case "description":
 				v = &acc.Description
+			case "slots":
+				v = &acc.Slots
+			case "lots":
+				acc.Lots, err = lotsUnmarshalXML(decoder)
+				for _, lot := range acc.Lots {
+					lot.Account = &acc
+				}
 			case "id":
-				v = &ID
+				v = &acc.ID
 			case "parent":
 				v = &ParentID
 			case "type":
@@ -97,13 +121,18 @@ LOOP:
 			case "commodity-scu":
 				v = &acc.CommodityScu
 			case "non-standard-scu":
-				v = &acc.NonStandardScu
+				// empty element: its presence sets the flag
+				acc.NonStandardScu = true
+				err = decoder.Skip()
+			default:
+				err = acc.addElement(decoder, &se)
 			}
 
 			if v != nil {
-				if err = decoder.DecodeElement(v, &se); err != nil {
-					return
-				}
+				err = decoder.DecodeElement(v, &se)
+			}
+			if err != nil {
+				return nil, "", parseError(decoder, se.Name, err)
 			}
 		case xml.EndElement:
 			if se.Name.Local == "account" {
@@ -111,10 +140,11 @@ LOOP:
 			}
 		}
 	}
-	if ID == "" || acc.Name == "" {
+	if acc.ID == "" || acc.Name == "" {
 		err = fmt.Errorf("Account without ID or Name")
 		return
 	}
+	acc.ParentID = ParentID
 
 	a = &acc
 	return
@@ -199,10 +229,6 @@ func (accounts *Accounts) PrintTree(indent string) {
 		return
 	}
 
-	if accounts.Root == nil {
-		fmt.Println("<root-nil>")
-		return
-	}
 	var pr func(*Account, int, string)
 
 	pr = func(a *Account, level int, indent string) {
@@ -212,8 +238,221 @@ func (accounts *Accounts) PrintTree(indent string) {
 		}
 	}
 
-	pr(accounts.Root, 0, indent)
+	if accounts.Root == nil {
+		fmt.Println("<root-nil>")
+	} else {
+		pr(accounts.Root, 0, indent)
+	}
+	if len(accounts.Orphans) > 0 {
+		fmt.Println("<orphans>")
+		for _, a := range accounts.Orphans {
+			pr(a, 1, indent)
+		}
+	}
+}
+
+// SCU returns the smallest commodity unit of the account, as the
+// denominator of its amounts, following xaccAccountGetCommoditySCU:
+// it is the fraction of the commodity, unless the account has a non
+// standard SCU. The commodity-scu of the account is used for the
+// currencies, whose fraction is not stored by the XML backend.
+func (a *Account) SCU() int64 {
+	if a.NonStandardScu || a.Currency == nil || (a.Currency.Fraction == "" && a.CommodityScu > 0) {
+		if a.CommodityScu <= 0 {
+			return 100
+		}
+		return int64(a.CommodityScu)
+	}
+	return a.Currency.SCU()
+}
+
+// Round returns the amount rounded to the SCU of the account,
+// half up as GnuCash does setting the amount of a split.
+func (a *Account) Round(amount *types.Numeric) *types.Numeric {
+	return amount.Convert(a.SCU(), types.RoundHalfUp)
+}
+
+// Notes returns the notes of the account.
+func (a *Account) Notes() string {
+	return a.Slots.GetString("notes")
+}
+
+// Placeholder returns true if the account is a placeholder,
+// i.e. it cannot directly hold transactions.
+func (a *Account) Placeholder() bool {
+	return a.Slots.GetBool("placeholder")
+}
+
+// Hidden returns true if the account is hidden.
+func (a *Account) Hidden() bool {
+	return a.Slots.GetBool("hidden")
+}
+
+// insert adds acc to the accounts, with the GUID of its parent.
+// The account is linked to its parent by resolve, once all the accounts
+// are inserted. accMap is updated with the new account.
+func (accounts *Accounts) insert(acc *Account, parentID types.GUID, accMap AccountMap) error {
+	if _, ok := accMap[acc.ID]; ok {
+		return fmt.Errorf("Duplicate account: %s", acc.ID)
+	}
+	acc.ParentID = parentID
+	// add new account in accounts list
+	accounts.Add(acc)
+	// update map from id -> account
+	accMap[acc.ID] = acc
+	return nil
+}
+
+// resolve links every account to its parent, looked up in accMap, so that
+// a child may come before its parent. It returns the accounts of type ROOT
+// without parent, in order: the caller chooses the root among them.
+// The other accounts without parent, with a missing parent or in a cycle
+// of parents, become the orphans. It can be called again after new
+// accounts are inserted.
+func (accounts *Accounts) resolve(accMap AccountMap) (roots []*Account) {
+	for _, acc := range accounts.List {
+		acc.Parent = nil
+		acc.Children = nil
+	}
+	accounts.Root = nil
+	accounts.Orphans = nil
+
+	for _, acc := range accounts.List {
+		if acc.ParentID == "" {
+			if acc.Type == types.AccountTypeRoot {
+				roots = append(roots, acc)
+			} else {
+				accounts.Orphans = append(accounts.Orphans, acc)
+			}
+			continue
+		}
+		parent, ok := accMap[acc.ParentID]
+		if !ok || parent == acc {
+			accounts.Orphans = append(accounts.Orphans, acc)
+			continue
+		}
+		acc.Parent = parent
+		parent.Children = append(parent.Children, acc)
+	}
 
+	reached := map[*Account]bool{}
+	var mark func(a *Account)
+	mark = func(a *Account) {
+		reached[a] = true
+		for _, child := range a.Children {
+			mark(child)
+		}
+	}
+	for _, list := range [][]*Account{roots, accounts.Orphans} {
+		for _, a := range list {
+			mark(a)
+		}
+	}
+
+	// the accounts not reached are in a cycle or descend from one:
+	// the cycle is broken at its first account met going up
+	for _, acc := range accounts.List {
+		if reached[acc] {
+			continue
+		}
+		a, seen := acc, map[*Account]bool{}
+		for !seen[a] {
+			seen[a] = true
+			a = a.Parent
+		}
+		a.Parent.removeChild(a)
+		a.Parent = nil
+		accounts.Orphans = append(accounts.Orphans, a)
+		mark(a)
+	}
+	return roots
+}
+
+// setRoot sets the root of the accounts among the roots returned by resolve:
+// it is the first root not looking as a template root. The other roots
+// not looking as template roots become orphans; the template roots are
+// returned.
+func (accounts *Accounts) setRoot(roots []*Account) (templateRoots []*Account) {
+	for _, r := range roots {
+		switch {
+		case r.isTemplateRoot() && len(roots) > 1:
+			templateRoots = append(templateRoots, r)
+		case accounts.Root == nil:
+			accounts.Root = r
+		default:
+			accounts.Orphans = append(accounts.Orphans, r)
+		}
+	}
+	return templateRoots
+}
+
+// isTemplateRoot returns true if the root account looks as the root of
+// the template accounts of the scheduled transactions: its name is
+// "Template Root", or every descendant is named after a GUID.
+func (a *Account) isTemplateRoot() bool {
+	if a.Name == "Template Root" {
+		return true
+	}
+	var guidNames func(a *Account) bool
+	guidNames = func(a *Account) bool {
+		for _, child := range a.Children {
+			if !isGUID(child.Name) || !guidNames(child) {
+				return false
+			}
+		}
+		return true
+	}
+	return len(a.Children) > 0 && guidNames(a)
+}
+
+// isGUID returns true if s is a GUID, as 32 hexadecimal digits.
+func isGUID(s string) bool {
+	if len(s) != 32 {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// removeChild removes child from the children of the account.
+func (a *Account) removeChild(child *Account) {
+	for j, c := range a.Children {
+		if c == child {
+			a.Children = append(a.Children[:j], a.Children[j+1:]...)
+			return
+		}
+	}
+}
+
+// remove removes the account and its descendants from the accounts
+// and from accMap, returning them in the order of the list.
+func (accounts *Accounts) remove(acc *Account, accMap AccountMap) []*Account {
+	subtree := map[*Account]bool{}
+	var mark func(a *Account)
+	mark = func(a *Account) {
+		subtree[a] = true
+		for _, child := range a.Children {
+			mark(child)
+		}
+	}
+	mark(acc)
+
+	var removed []*Account
+	list := accounts.List[:0]
+	for _, a := range accounts.List {
+		if subtree[a] {
+			removed = append(removed, a)
+			delete(accMap, a.ID)
+			continue
+		}
+		list = append(list, a)
+	}
+	accounts.List = list
+	return removed
 }
 
 func (accounts *Accounts) Add(acc *Account) {
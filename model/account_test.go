@@ -0,0 +1,136 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// testOutOfOrderAccounts has the children before their parents, a template
+// root with its template account among the accounts of the book, and an
+// account whose parent is missing.
+const testOutOfOrderAccounts = `<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c2</act:id>
+  <act:type>BANK</act:type>
+  <act:parent type="guid">000000000000000000000000000000c1</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Assets</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>ASSET</act:type>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>2ab3bd0b20e0a61c5e2a4d5a76d26f1a</act:name>
+  <act:id type="guid">000000000000000000000000000000b1</act:id>
+  <act:type>BANK</act:type>
+  <act:parent type="guid">000000000000000000000000000000b0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Template Root</act:name>
+  <act:id type="guid">000000000000000000000000000000b0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Lost</act:name>
+  <act:id type="guid">000000000000000000000000000000c9</act:id>
+  <act:type>EXPENSE</act:type>
+  <act:parent type="guid">000000000000000000000000000000ff</act:parent>
+</gnc:account>
+`
+
+func TestAccountsOutOfOrder(t *testing.T) {
+	gnc, err := Read(strings.NewReader(testXMLHeader + testOutOfOrderAccounts + testXMLFooter))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	book := gnc.Book
+
+	if root := book.Accounts.Root; root == nil || root.Name != "Root Account" {
+		t.Fatalf("Root: got %v", root)
+	}
+	bank := book.AccountMap["000000000000000000000000000000c2"]
+	if bank == nil || bank.FullName() != "Root Account/Assets/Bank" {
+		t.Errorf("Bank: unexpected account %v", bank)
+	}
+	if n := book.Accounts.Len(); n != 4 {
+		t.Errorf("Accounts: expected 4, got %d", n)
+	}
+
+	// the template root is moved to the template accounts
+	if root := book.TemplateAccounts.Root; root == nil || root.Name != "Template Root" || len(root.Children) != 1 {
+		t.Errorf("TemplateAccounts.Root: got %v", root)
+	}
+	if n := book.TemplateAccounts.Len(); n != 2 {
+		t.Errorf("TemplateAccounts: expected 2, got %d", n)
+	}
+	if book.AccountMap["000000000000000000000000000000b1"] != nil {
+		t.Errorf("AccountMap: unexpected template account")
+	}
+
+	orphans := book.Accounts.Orphans
+	if len(orphans) != 1 || orphans[0].Name != "Lost" || orphans[0].Parent != nil {
+		t.Fatalf("Orphans: got %v", orphans)
+	}
+	if len(gnc.Warnings) != 1 || !strings.Contains(gnc.Warnings[0].Error(), "parent not found: 000000000000000000000000000000ff") {
+		t.Errorf("Warnings: got %v", gnc.Warnings)
+	}
+}
+
+func TestAccountSCU(t *testing.T) {
+	eur := &Commodity{Space: "ISO4217", ID: "EUR"}
+	acme := &Commodity{Space: "NASDAQ", ID: "ACME", Fraction: "10000"}
+	var testCases = []struct {
+		acc      Account
+		expected int64
+		rounded  string
+	}{
+		{Account{Currency: eur, CommodityScu: 100}, 100, "1235/100"},
+		{Account{Currency: eur}, 100, "1235/100"},
+		{Account{Currency: acme, CommodityScu: 100}, 10000, "123450/10000"},
+		{Account{Currency: acme, CommodityScu: 10, NonStandardScu: true}, 10, "123/10"},
+		{Account{CommodityScu: 1000}, 1000, "12345/1000"},
+	}
+	for j, tc := range testCases {
+		if actual := tc.acc.SCU(); actual != tc.expected {
+			t.Errorf("%d: SCU: expected %d, got %d", j, tc.expected, actual)
+		}
+		if actual := tc.acc.Round(types.New(12345, 1000)).GncString(); actual != tc.rounded {
+			t.Errorf("%d: Round: expected %s, got %s", j, tc.rounded, actual)
+		}
+	}
+}
+
+func TestAccountsCycle(t *testing.T) {
+	a := &Account{ID: "a", Name: "A", ParentID: "b"}
+	b := &Account{ID: "b", Name: "B", ParentID: "a"}
+	c := &Account{ID: "c", Name: "C", ParentID: "b"}
+	var accounts Accounts
+	accMap := AccountMap{}
+	for _, acc := range []*Account{a, b, c} {
+		if err := accounts.insert(acc, acc.ParentID, accMap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := accounts.insert(&Account{ID: "a"}, "", accMap); err == nil {
+		t.Errorf("insert: expected duplicate account error")
+	}
+
+	if roots := accounts.resolve(accMap); len(roots) != 0 {
+		t.Errorf("roots: expected none, got %v", roots)
+	}
+	// the cycle is broken at A, the first account
+	if len(accounts.Orphans) != 1 || accounts.Orphans[0] != a || a.Parent != nil {
+		t.Fatalf("Orphans: got %v", accounts.Orphans)
+	}
+	if b.Parent != a || c.Parent != b || len(b.Children) != 1 || b.Children[0] != c {
+		t.Errorf("unexpected tree: %s, %s", b.FullName(), c.FullName())
+	}
+}
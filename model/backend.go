@@ -0,0 +1,142 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Capabilities type is the set of operations supported by a backend.
+type Capabilities int
+
+// Capabilities constants
+const (
+	// CanRead is set by the backends loading a whole book.
+	CanRead Capabilities = 1 << iota
+	// CanWrite is set by the backends saving a whole book.
+	CanWrite
+	// CanIncremental is set by the backends saving the changed objects only.
+	CanIncremental
+)
+
+var capabilityLabels = []string{"read", "write", "incremental"}
+
+func (c Capabilities) String() string {
+	var labels []string
+	for j, label := range capabilityLabels {
+		if c&(1<<uint(j)) != 0 {
+			labels = append(labels, label)
+		}
+	}
+	return strings.Join(labels, ",")
+}
+
+// Backend interface is a storage format of GnuCash books.
+// A backend is made available by Register.
+type Backend interface {
+	// Format returns the name of the format, as "xml" or "sqlite".
+	Format() string
+	// Capabilities returns the operations supported by the backend.
+	Capabilities() Capabilities
+	// Detect returns true if a file starting with header is in the
+	// format of the backend. The header has at most headerSize bytes.
+	Detect(header []byte) bool
+	// Load reads the file. The backends may ignore the options
+	// not applying to their format.
+	Load(path string, opts *ReadOptions) (*Gnc, error)
+}
+
+// Saver interface is implemented by the backends with the CanWrite capability.
+type Saver interface {
+	// Save writes the file.
+	Save(path string, gnc *Gnc) error
+}
+
+// headerSize is the number of bytes of a file passed to Backend.Detect.
+const headerSize = 512
+
+// backends are the registered backends, in registration order.
+var backends []Backend
+
+// Register makes a backend available by its format.
+// It panics if a backend of the same format is already registered.
+func Register(b Backend) {
+	if Lookup(b.Format()) != nil {
+		panic("model: backend already registered: " + b.Format())
+	}
+	backends = append(backends, b)
+}
+
+// Lookup returns the backend of the format, or nil if not registered.
+func Lookup(format string) Backend {
+	for _, b := range backends {
+		if b.Format() == format {
+			return b
+		}
+	}
+	return nil
+}
+
+// Formats returns the sorted formats of the registered backends.
+func Formats() []string {
+	formats := make([]string, len(backends))
+	for j, b := range backends {
+		formats[j] = b.Format()
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// Detect returns the backend of the file, by its signature.
+func Detect(path string) (Backend, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header, _ := bufio.NewReaderSize(f, headerSize).Peek(headerSize)
+	for _, b := range backends {
+		if b.Detect(header) {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown format of file %s", path)
+}
+
+// LoadFile reads the file with the backend of the given format;
+// an empty format is detected by the file signature.
+// A nil opts is the same as the zero ReadOptions.
+func LoadFile(path, format string, opts *ReadOptions) (*Gnc, error) {
+	var (
+		b   Backend
+		err error
+	)
+	if format == "" {
+		b, err = Detect(path)
+	} else if b = Lookup(format); b == nil {
+		err = fmt.Errorf("Unknown format: %s", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.Capabilities()&CanRead == 0 {
+		return nil, fmt.Errorf("Format %s cannot be read", b.Format())
+	}
+	return b.Load(path, opts)
+}
+
+// SaveFile writes the file with the backend of the given format.
+func SaveFile(path, format string, gnc *Gnc) error {
+	b := Lookup(format)
+	if b == nil {
+		return fmt.Errorf("Unknown format: %s", format)
+	}
+	s, ok := b.(Saver)
+	if !ok || b.Capabilities()&CanWrite == 0 {
+		return fmt.Errorf("Format %s cannot be written", format)
+	}
+	return s.Save(path, gnc)
+}
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	expected := []string{"accounts-template", "sqlite", "xml", "xml-gz"}
+	if actual := Formats(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Formats: expected %v, got %v", expected, actual)
+	}
+
+	var testCases = []struct {
+		format string
+		caps   string
+	}{
+		{"xml", "read,write"},
+		{"xml-gz", "read,write"},
+		{"accounts-template", "read,write"},
+		{"sqlite", "read"},
+	}
+	for _, tc := range testCases {
+		if actual := Lookup(tc.format).Capabilities().String(); actual != tc.caps {
+			t.Errorf("Capabilities(%s): expected %q, got %q", tc.format, tc.caps, actual)
+		}
+	}
+	if Lookup("csv") != nil {
+		t.Errorf("Lookup(csv): expected nil")
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register: expected panic for a duplicated format")
+		}
+	}()
+	Register(xmlBackend{})
+}
+
+func TestSaveLoadFile(t *testing.T) {
+	book := readTestBook(t, testInvoiceBook)
+	expected := writeTestBook(t, book)
+	dir := t.TempDir()
+
+	for _, format := range []string{"xml", "xml-gz"} {
+		path := filepath.Join(dir, format+".gnucash")
+		if err := SaveFile(path, format, &Gnc{Book: book}); err != nil {
+			t.Fatalf("SaveFile(%s): unexpected error: %s", format, err)
+		}
+		b, err := Detect(path)
+		if err != nil {
+			t.Fatalf("Detect(%s): unexpected error: %s", format, err)
+		}
+		if b.Format() != format {
+			t.Errorf("Detect: expected %s, got %s", format, b.Format())
+		}
+		gnc, err := LoadFile(path, "", nil)
+		if err != nil {
+			t.Fatalf("LoadFile(%s): unexpected error: %s", format, err)
+		}
+		if actual := writeTestBook(t, gnc.Book); actual != expected {
+			t.Errorf("LoadFile(%s): mismatch:\n%s\n---\n%s", format, expected, actual)
+		}
+	}
+
+	if err := SaveFile(filepath.Join(dir, "book.sqlite"), "sqlite", &Gnc{Book: book}); err == nil {
+		t.Errorf("SaveFile(sqlite): expected error")
+	}
+	if _, err := LoadFile(filepath.Join(dir, "xml.gnucash"), "csv", nil); err == nil {
+		t.Errorf("LoadFile(csv): expected error")
+	}
+
+	path := filepath.Join(dir, "unknown")
+	if err := os.WriteFile(path, []byte("name,amount\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Detect(path); err == nil {
+		t.Errorf("Detect: expected error for an unknown format")
+	}
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "common.gnucash-xea")
+	if err := os.WriteFile(path, []byte(testChartTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := Detect(path); err != nil || b.Format() != "accounts-template" {
+		t.Fatalf("Detect: expected accounts-template, got %v, %v", b, err)
+	}
+	gnc, err := LoadFile(path, "accounts-template", nil)
+	if err != nil {
+		t.Fatalf("LoadFile: unexpected error: %s", err)
+	}
+	if gnc.Chart == nil || gnc.Chart.Title != "Common Accounts" {
+		t.Fatalf("LoadFile: unexpected chart %+v", gnc.Chart)
+	}
+
+	// the chart of an accounts-only file is saved as an account template
+	gnc.XMLName.Local = "gnc-v2"
+	path = filepath.Join(dir, "saved.gnucash-xea")
+	if err := SaveFile(path, "accounts-template", gnc); err != nil {
+		t.Fatalf("SaveFile: unexpected error: %s", err)
+	}
+	gnc2, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: unexpected error: %s", err)
+	}
+	if gnc2.XMLName.Local != "gnc-account-example" || gnc2.Chart.Title != "Common Accounts" {
+		t.Errorf("ReadFile: unexpected template %s %+v", gnc2.XMLName.Local, gnc2.Chart)
+	}
+
+	// a book is not an account template
+	book := readTestBook(t, testInvoiceBook)
+	path = filepath.Join(dir, "book.gnucash")
+	if err := SaveFile(path, "accounts-template", &Gnc{Book: book}); err == nil {
+		t.Errorf("SaveFile: expected error for a book")
+	}
+	if err := SaveFile(path, "xml", &Gnc{Book: book}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path, "accounts-template", nil); err == nil {
+		t.Errorf("LoadFile: expected error for a book")
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+BillTerm = element gnc:GncBillTerm {
+  attribute version { "2.0.0" },
+  element billterm:guid { attribute type { "guid" }, GUID }?,
+  element billterm:name { text },
+  element billterm:desc { text },
+  element billterm:refcount { xsd:int },
+  element billterm:invisible { xsd:boolean { pattern = "[01]" } },
+  element billterm:slots { KvpSlot+ }?,
+  element billterm:child { attribute type { "guid" }, GUID }?,
+  element billterm:parent { attribute type { "guid" }, GUID }?,
+  ( element billterm:days {
+      element bt-days:due-days { xsd:int }?,
+      element bt-days:disc-days { xsd:int }?,
+      element bt-days:discount { GncNumeric }?
+    }
+  | element billterm:proximo {
+      element bt-prox:due-day { xsd:int }?,
+      element bt-prox:disc-day { xsd:int }?,
+      element bt-prox:discount { GncNumeric }?,
+      element bt-prox:cutoff-day { xsd:int }?
+    }
+  )
+}
+*/
+
+// BillTerm type
+type BillTerm struct {
+	ID          types.GUID `xml:"guid"`
+	Name        string     `xml:"name"`
+	Description string     `xml:"desc"`
+	RefCount    int        `xml:"refcount"`
+	Invisible   bool       `xml:"invisible"`
+	Slots       Slots      `xml:"slots"`
+	ChildID     types.GUID `xml:"child"`
+	ParentID    types.GUID `xml:"parent"`
+	// Only one of Days and Proximo is set.
+	Days    *BillTermDays    `xml:"days"`
+	Proximo *BillTermProximo `xml:"proximo"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// BillTermDays type: the due date is a number of days after the post date.
+type BillTermDays struct {
+	DueDays  int           `xml:"due-days"`
+	DiscDays int           `xml:"disc-days"`
+	Discount types.Numeric `xml:"discount"`
+}
+
+// BillTermProximo type: the due date is a day of the following month(s).
+type BillTermProximo struct {
+	DueDay    int           `xml:"due-day"`
+	DiscDay   int           `xml:"disc-day"`
+	Discount  types.Numeric `xml:"discount"`
+	CutoffDay int           `xml:"cutoff-day"`
+}
+
+// DueDate returns the due date of a document posted at the given date.
+func (bt *BillTerm) DueDate(posted time.Time) time.Time {
+	switch {
+	case bt.Days != nil:
+		return posted.AddDate(0, 0, bt.Days.DueDays)
+	case bt.Proximo != nil:
+		return proximoDate(posted, bt.Proximo.DueDay, bt.Proximo.CutoffDay)
+	}
+	return posted
+}
+
+// DiscountDate returns the last date to take the early payment discount.
+func (bt *BillTerm) DiscountDate(posted time.Time) time.Time {
+	switch {
+	case bt.Days != nil:
+		return posted.AddDate(0, 0, bt.Days.DiscDays)
+	case bt.Proximo != nil:
+		return proximoDate(posted, bt.Proximo.DiscDay, bt.Proximo.CutoffDay)
+	}
+	return posted
+}
+
+// proximoDate reproduces compute_monthyear and compute_time of gncBillTerm.c:
+// the day of the next month, or of the month after if the post date
+// is beyond the cutoff day.
+// A cutoff day not greater than zero counts back from the end of the month.
+func proximoDate(posted time.Time, day, cutoff int) time.Time {
+	y, m, d := posted.Date()
+	if cutoff <= 0 {
+		cutoff += daysInMonth(y, m)
+	}
+	if d <= cutoff {
+		m++
+	} else {
+		m += 2
+	}
+	t := time.Date(y, m, 1, 0, 0, 0, 0, posted.Location())
+	if n := daysInMonth(t.Year(), t.Month()); day > n {
+		day = n
+	}
+	return time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, posted.Location())
+}
+
+// daysInMonth returns the number of days of the month.
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
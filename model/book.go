@@ -2,8 +2,11 @@ package model
 
 import (
 	"encoding/xml"
-	"errors"
 	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mmbros/gnucash-viewer/types"
 )
 
 /*
@@ -61,79 +64,407 @@ Book = element gnc:book {
 
 // Book type
 type Book struct {
-	Commodities Commodities
-	//PriceDb?,
+	ID           types.GUID
+	Slots        Slots
+	Commodities  Commodities
+	PriceDB      PriceDB
 	Accounts     Accounts
 	Transactions Transactions
+
+	TemplateAccounts      Accounts
+	TemplateTransactions  Transactions
+	ScheduledTransactions ScheduledTransactions
+	Budgets               Budgets
+
+	Business Business
+
+	// Counts are the counters of the objects by type, as read from the
+	// count-data elements: they are checked by Check and not written,
+	// the writer computing its own counters.
+	Counts map[string]int
+
+	// GUID indexes of the book objects
+	AccountMap     AccountMap
+	TransactionMap TransactionMap
+	SplitMap       SplitMap
+	LotMap         LotMap
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// Features returns the names of the features used by the book,
+// as listed in the "features" frame of the book slots.
+func (b *Book) Features() []string {
+	v := b.Slots.Get("features")
+	if v == nil || v.Type != KvpTypeFrame {
+		return nil
+	}
+	features := make([]string, len(v.Frame))
+	for j, s := range v.Frame {
+		features[j] = s.Key
+	}
+	return features
+}
+
+// Company returns the name and address of the company owning the book,
+// as set in the Business options of the book.
+func (b *Book) Company() Address {
+	const prefix = "options/Business/"
+	var a Address
+	a.Name = b.Slots.GetString(prefix + "Company Name")
+	lines := strings.Split(strings.TrimSpace(b.Slots.GetString(prefix+"Company Address")), "\n")
+	for j, p := range []*string{&a.Addr1, &a.Addr2, &a.Addr3, &a.Addr4} {
+		if j < len(lines) {
+			*p = strings.TrimSpace(lines[j])
+		}
+	}
+	a.Phone = b.Slots.GetString(prefix + "Company Phone Number")
+	a.Fax = b.Slots.GetString(prefix + "Company Fax Number")
+	a.Email = b.Slots.GetString(prefix + "Company Email Address")
+	return a
 }
 
 // UnmarshalXML implements xml.Unmarshaler interface
 func (b *Book) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	return b.unmarshalXML(decoder, start, &bookReader{})
+}
+
+// bookReader type holds the state of the decoding of a book,
+// or of an account chart.
+type bookReader struct {
+	book        *Book
+	chart       *Chart
+	accMap      AccountMap
+	lotMap      LotMap
+	templAccMap AccountMap
+
+	// lenient, if true, skips the objects of the book with errors,
+	// keeping the errors in warnings.
+	lenient  bool
+	warnings []error
+	// path are the names of the book and of its ancestors.
+	path []xml.Name
+}
+
+// warn adds err, found in the book, to the warnings.
+func (r *bookReader) warn(err *ParseError) {
+	for j := len(r.path) - 1; j >= 0; j-- {
+		err.Path = append([]string{qualifiedName(r.path[j], nil)}, err.Path...)
+	}
+	r.warnings = append(r.warnings, err)
+}
+
+func (b *Book) unmarshalXML(decoder *xml.Decoder, start xml.StartElement, r *bookReader) error {
 	// http://stackoverflow.com/questions/17301149/golang-xml-unmarshal-and-time-time-fields
 	// http://blog.davidsingleton.org/parsing-huge-xml-files-with-go/
 
-	var (
-		book   Book
-		accMap AccountMap
-	)
-	accMap = AccountMap{}
+	var book Book
+	r.book = &book
+	r.accMap = AccountMap{}
+	r.lotMap = LotMap{}
+	r.templAccMap = AccountMap{}
+	r.path = append(r.path, start.Name)
+	book.addAttrs(start.Attr)
 
+LOOP:
 	for {
 		// Read tokens from the XML document in a stream.
-		t, _ := decoder.Token()
-		if t == nil {
-			break
+		t, err := decoder.Token()
+		if err != nil {
+			return parseError(decoder, start.Name, err)
 		}
 		// Inspect the type of the token just read.
 		switch se := t.(type) {
 		case xml.StartElement:
-			switch se.Name.Local {
-			case "commodity":
-				var cmdty Commodity
-				decoder.DecodeElement(&cmdty, &se)
-				book.Commodities.Add(&cmdty)
-			case "account":
-				account, id, parentID, err := AccountUnmarshalXML(decoder, book.Commodities)
-				if err != nil {
-					return err
-				}
-				// add new account in book's accounts
-				book.Accounts.Add(account)
-				// update map from id -> account
-				accMap[id] = account
-				if parentID == "" {
-					// root account
-					if book.Accounts.Root != nil {
-						return errors.New("Invalid multi root accounts")
-					}
-					book.Accounts.Root = account
-				} else {
-					// setup parent and children values
-					parent, ok := accMap[parentID]
-					if ok {
-						account.Parent = parent
-						parent.Children = append(parent.Children, account)
-					} else {
-						return fmt.Errorf("Parent account not (yes) found: parent=%s", parentID)
-					}
-				}
-			case "transaction":
-				trn, err := transactionUnmarshalXML(decoder, book.Commodities, accMap)
-				if err != nil {
-					return err
-				}
-				book.Transactions.Add(trn)
-
+			if r.lenient {
+				err = r.isolated(decoder, se, r.object)
+			} else if err = r.object(decoder, se); err != nil {
+				err = parseError(decoder, se.Name, err)
+			}
+			if err != nil {
+				return parseError(decoder, start.Name, err)
 			}
-
 		case xml.EndElement:
 			if se.Name.Local == "book" {
-				book.Transactions.Sort()
-				break
+				break LOOP
 			}
 		}
 	}
+	r.warnings = append(r.warnings, book.resolveAccounts(r.accMap, r.templAccMap)...)
+	book.Transactions.Sort()
+	book.AccountMap = r.accMap
+	book.LotMap = r.lotMap
+	book.indexTransactions()
+	for _, err := range append(book.linkTemplates(r.templAccMap), book.Business.link(&book)...) {
+		if !r.lenient {
+			return parseError(decoder, start.Name, err)
+		}
+		r.warn(parseError(decoder, xml.Name{}, err).(*ParseError))
+	}
 	*b = book
 
 	return nil
 }
+
+// object reads the child element of the book started by se.
+func (r *bookReader) object(decoder *xml.Decoder, se xml.StartElement) error {
+	book := r.book
+
+	switch se.Name.Local {
+	case "id":
+		if se.Name.Space != nsBook {
+			return book.addElement(decoder, &se)
+		}
+		return decoder.DecodeElement(&book.ID, &se)
+	case "slots":
+		if se.Name.Space != nsBook {
+			return book.addElement(decoder, &se)
+		}
+		return decoder.DecodeElement(&book.Slots, &se)
+	case "count-data":
+		var n int
+		if err := decoder.DecodeElement(&n, &se); err != nil {
+			return err
+		}
+		for _, a := range se.Attr {
+			if a.Name.Local == "type" {
+				if book.Counts == nil {
+					book.Counts = map[string]int{}
+				}
+				book.Counts[a.Value] = n
+			}
+		}
+	case "commodity":
+		var cmdty Commodity
+		if err := decoder.DecodeElement(&cmdty, &se); err != nil {
+			return err
+		}
+		book.Commodities.Add(&cmdty)
+	case "pricedb":
+		db, err := priceDBUnmarshalXML(decoder, book.Commodities)
+		if err != nil {
+			return err
+		}
+		book.PriceDB = *db
+	case "account":
+		account, parentID, err := AccountUnmarshalXML(decoder, book.Commodities)
+		if err != nil {
+			return err
+		}
+		account.addAttrs(se.Attr)
+		// add new account in book's accounts
+		if err := book.Accounts.insert(account, parentID, r.accMap); err != nil {
+			return err
+		}
+		for _, lot := range account.Lots {
+			r.lotMap[lot.ID] = lot
+		}
+	case "transaction":
+		trn, err := transactionUnmarshalXML(decoder, book.Commodities, r.accMap, r.lotMap)
+		if err != nil {
+			return err
+		}
+		trn.addAttrs(se.Attr)
+		book.Transactions.Add(trn)
+	case "template-transactions":
+		return templateTransactionsUnmarshalXML(decoder, book, r.accMap, r.templAccMap)
+	case "schedxaction":
+		sx, err := scheduledTransactionUnmarshalXML(decoder)
+		if err != nil {
+			return err
+		}
+		sx.addAttrs(se.Attr)
+		book.ScheduledTransactions.Add(sx)
+	case "budget":
+		var bgt Budget
+		if err := decoder.DecodeElement(&bgt, &se); err != nil {
+			return err
+		}
+		book.Budgets.Add(&bgt)
+	case "GncCustomer":
+		var c Customer
+		if err := decoder.DecodeElement(&c, &se); err != nil {
+			return err
+		}
+		book.Business.Customers = append(book.Business.Customers, &c)
+	case "GncVendor":
+		var v Vendor
+		if err := decoder.DecodeElement(&v, &se); err != nil {
+			return err
+		}
+		book.Business.Vendors = append(book.Business.Vendors, &v)
+	case "GncEmployee":
+		var e Employee
+		if err := decoder.DecodeElement(&e, &se); err != nil {
+			return err
+		}
+		book.Business.Employees = append(book.Business.Employees, &e)
+	case "GncJob":
+		var j Job
+		if err := decoder.DecodeElement(&j, &se); err != nil {
+			return err
+		}
+		book.Business.Jobs = append(book.Business.Jobs, &j)
+	case "GncInvoice":
+		var inv Invoice
+		if err := decoder.DecodeElement(&inv, &se); err != nil {
+			return err
+		}
+		book.Business.Invoices = append(book.Business.Invoices, &inv)
+	case "GncEntry":
+		var e Entry
+		if err := decoder.DecodeElement(&e, &se); err != nil {
+			return err
+		}
+		book.Business.Entries = append(book.Business.Entries, &e)
+	case "GncOrder":
+		var o Order
+		if err := decoder.DecodeElement(&o, &se); err != nil {
+			return err
+		}
+		book.Business.Orders = append(book.Business.Orders, &o)
+	case "GncBillTerm":
+		var bt BillTerm
+		if err := decoder.DecodeElement(&bt, &se); err != nil {
+			return err
+		}
+		book.Business.BillTerms = append(book.Business.BillTerms, &bt)
+	case "GncTaxTable":
+		var tt TaxTable
+		if err := decoder.DecodeElement(&tt, &se); err != nil {
+			return err
+		}
+		book.Business.TaxTables = append(book.Business.TaxTables, &tt)
+	default:
+		return book.addElement(decoder, &se)
+	}
+	return nil
+}
+
+// isolated reads with object the element started by se, child of the
+// book or of the chart, so that an error in the object is kept as a
+// warning and the reading goes on with the next object: the rest of the
+// element is skipped. Only the syntax errors of the document are returned.
+func (r *bookReader) isolated(decoder *xml.Decoder, se xml.StartElement, object func(*xml.Decoder, xml.StartElement) error) error {
+	er := &elementReader{decoder: decoder, start: &se}
+	sub := xml.NewTokenDecoder(er)
+	t, err := sub.Token()
+	if err != nil {
+		return parseError(decoder, se.Name, err)
+	}
+	if err = object(sub, t.(xml.StartElement)); err == nil {
+		return nil
+	}
+
+	// the tokens are read from decoder: its position is the one of the error
+	pe := parseError(sub, se.Name, err).(*ParseError)
+	pe.Line, pe.Column = decoder.InputPos()
+	pe.Offset = decoder.InputOffset()
+	if er.err != nil {
+		return pe
+	}
+	r.warn(pe)
+	for ; er.depth > 0; er.depth-- {
+		if err := decoder.Skip(); err != nil {
+			return parseError(decoder, se.Name, err)
+		}
+	}
+	return nil
+}
+
+// elementReader type is the xml.TokenReader of the tokens of an element,
+// read from the decoder of the whole document.
+type elementReader struct {
+	decoder *xml.Decoder
+	// start is the start element, returned by the first call
+	start *xml.StartElement
+	// depth is the number of the elements not yet ended
+	depth int
+	// err is the error of decoder
+	err error
+}
+
+// Token implements xml.TokenReader interface.
+// It returns io.EOF after the end of the element.
+func (er *elementReader) Token() (xml.Token, error) {
+	if er.start != nil {
+		se := *er.start
+		er.start = nil
+		er.depth = 1
+		return se, nil
+	}
+	if er.depth == 0 {
+		return nil, io.EOF
+	}
+	t, err := er.decoder.Token()
+	if err != nil {
+		er.err = err
+		return nil, err
+	}
+	switch t.(type) {
+	case xml.StartElement:
+		er.depth++
+	case xml.EndElement:
+		er.depth--
+	}
+	return xml.CopyToken(t), nil
+}
+
+// resolveAccounts links the accounts and the template accounts of the
+// book to their parents, once all of them are read. The template roots
+// found among the accounts, as written by some tools, are moved with
+// their descendants to the template accounts. An error is returned for
+// every orphan account, that is kept in the Orphans of its list.
+func (b *Book) resolveAccounts(accMap, templAccMap AccountMap) []error {
+	for _, root := range b.Accounts.setRoot(b.Accounts.resolve(accMap)) {
+		for _, acc := range b.Accounts.remove(root, accMap) {
+			b.TemplateAccounts.Add(acc)
+			templAccMap[acc.ID] = acc
+		}
+	}
+	roots := b.TemplateAccounts.resolve(templAccMap)
+	if len(roots) > 0 {
+		b.TemplateAccounts.Root = roots[0]
+		b.TemplateAccounts.Orphans = append(b.TemplateAccounts.Orphans, roots[1:]...)
+	}
+
+	var errs []error
+	for _, accounts := range []struct {
+		list   *Accounts
+		accMap AccountMap
+	}{{&b.Accounts, accMap}, {&b.TemplateAccounts, templAccMap}} {
+		for _, acc := range accounts.list.Orphans {
+			errs = append(errs, orphanError(acc, accounts.accMap))
+		}
+	}
+	return errs
+}
+
+// orphanError returns the error reporting the orphan account.
+func orphanError(acc *Account, accMap AccountMap) error {
+	switch _, ok := accMap[acc.ParentID]; {
+	case acc.ParentID == "":
+		return fmt.Errorf("Orphan account %s (%s): no parent", acc.Name, acc.ID)
+	case !ok:
+		return fmt.Errorf("Orphan account %s (%s): parent not found: %s", acc.Name, acc.ID, acc.ParentID)
+	}
+	return fmt.Errorf("Orphan account %s (%s): cycle of parents", acc.Name, acc.ID)
+}
+
+// indexTransactions builds the GUID indexes of transactions and splits,
+// and the list of splits of every lot.
+// Transactions must be already sorted.
+func (b *Book) indexTransactions() {
+	b.TransactionMap = TransactionMap{}
+	b.SplitMap = SplitMap{}
+	for _, t := range b.Transactions {
+		b.TransactionMap[t.ID] = t
+		for _, s := range t.Splits {
+			b.SplitMap[s.ID] = s
+			if s.Lot != nil {
+				s.Lot.Splits.Add(s)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+const testXMLHeader = `<?xml version="1.0" encoding="utf-8" ?>
+<gnc-v2
+     xmlns:gnc="http://www.gnucash.org/XML/gnc"
+     xmlns:act="http://www.gnucash.org/XML/act"
+     xmlns:book="http://www.gnucash.org/XML/book"
+     xmlns:cd="http://www.gnucash.org/XML/cd"
+     xmlns:cmdty="http://www.gnucash.org/XML/cmdty"
+     xmlns:price="http://www.gnucash.org/XML/price"
+     xmlns:slot="http://www.gnucash.org/XML/slot"
+     xmlns:split="http://www.gnucash.org/XML/split"
+     xmlns:trn="http://www.gnucash.org/XML/trn"
+     xmlns:ts="http://www.gnucash.org/XML/ts"
+     xmlns:lot="http://www.gnucash.org/XML/lot"
+     xmlns:sx="http://www.gnucash.org/XML/sx"
+     xmlns:recurrence="http://www.gnucash.org/XML/recurrence"
+     xmlns:bgt="http://www.gnucash.org/XML/bgt"
+     xmlns:addr="http://www.gnucash.org/XML/addr"
+     xmlns:owner="http://www.gnucash.org/XML/owner"
+     xmlns:cust="http://www.gnucash.org/XML/cust"
+     xmlns:employee="http://www.gnucash.org/XML/employee"
+     xmlns:job="http://www.gnucash.org/XML/job"
+     xmlns:vendor="http://www.gnucash.org/XML/vendor"
+     xmlns:invoice="http://www.gnucash.org/XML/invoice"
+     xmlns:entry="http://www.gnucash.org/XML/entry"
+     xmlns:order="http://www.gnucash.org/XML/order"
+     xmlns:billterm="http://www.gnucash.org/XML/billterm"
+     xmlns:bt-days="http://www.gnucash.org/XML/bt-days"
+     xmlns:bt-prox="http://www.gnucash.org/XML/bt-prox"
+     xmlns:taxtable="http://www.gnucash.org/XML/taxtable"
+     xmlns:tte="http://www.gnucash.org/XML/tte">
+<gnc:count-data cd:type="book">1</gnc:count-data>
+<gnc:book version="2.0.0">
+<book:id type="guid">00000000000000000000000000000001</book:id>
+<gnc:commodity version="2.0.0">
+  <cmdty:space>ISO4217</cmdty:space>
+  <cmdty:id>EUR</cmdty:id>
+</gnc:commodity>
+<gnc:commodity version="2.0.0">
+  <cmdty:space>NASDAQ</cmdty:space>
+  <cmdty:id>ACME</cmdty:id>
+  <cmdty:name>Acme Corp</cmdty:name>
+  <cmdty:fraction>10000</cmdty:fraction>
+</gnc:commodity>
+`
+
+const testXMLFooter = `</gnc:book>
+</gnc-v2>
+`
+
+// readTestBook parses a gnucash book from the given XML string
+// wrapped by testXMLHeader and testXMLFooter.
+func readTestBook(t *testing.T, body string) *Book {
+	var gnc Gnc
+	s := testXMLHeader + body + testXMLFooter
+	if err := xml.NewDecoder(strings.NewReader(s)).Decode(&gnc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	return gnc.Book
+}
+
+const testTransactionsBook = `<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Salary</act:name>
+  <act:id type="guid">000000000000000000000000000000c2</act:id>
+  <act:type>INCOME</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d1</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-01-27 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2016-01-27 10:59:00 +0100</ts:date></trn:date-entered>
+  <trn:description>January salary</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e1</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>150000/100</split:value>
+      <split:quantity>150000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e2</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-150000/100</split:value>
+      <split:quantity>-150000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c2</split:account>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+`
+
+func TestBookGUIDs(t *testing.T) {
+	book := readTestBook(t, testTransactionsBook)
+
+	if book.ID != "00000000000000000000000000000001" {
+		t.Errorf("Book.ID: got %q", book.ID)
+	}
+
+	bank := book.AccountMap["000000000000000000000000000000c1"]
+	if bank == nil || bank.Name != "Bank" || bank.ID != "000000000000000000000000000000c1" {
+		t.Fatalf("AccountMap: unexpected account %v", bank)
+	}
+
+	trn := book.TransactionMap["000000000000000000000000000000d1"]
+	if trn == nil || trn.Description != "January salary" {
+		t.Fatalf("TransactionMap: unexpected transaction %v", trn)
+	}
+
+	split := book.SplitMap["000000000000000000000000000000e2"]
+	if split == nil || split.Account.Name != "Salary" {
+		t.Fatalf("SplitMap: unexpected split %v", split)
+	}
+	if split.Transaction != trn {
+		t.Errorf("Split.Transaction: expected the owning transaction")
+	}
+	if len(book.SplitMap) != 2 {
+		t.Errorf("SplitMap: expected 2 splits, got %d", len(book.SplitMap))
+	}
+}
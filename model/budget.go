@@ -0,0 +1,73 @@
+package model
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Budget = element gnc:budget {
+  attribute version { "2.0.0" },
+  element bgt:id { attribute type { "guid" }, GUID },
+  element bgt:name { text },
+  element bgt:description { text },
+  element bgt:num-periods { xsd:int },
+  element bgt:recurrence { RecurrenceContent },
+  element bgt:slots { KvpSlot+ }?
+}
+*/
+
+// Budgets type is a list of Budget.
+type Budgets []*Budget
+
+// Budget type
+//
+// The budgeted amounts are stored in the slots: a frame for each account,
+// keyed by the account GUID, holding a numeric value for each period,
+// keyed by the period number.
+type Budget struct {
+	ID          types.GUID       `xml:"id"`
+	Name        string           `xml:"name"`
+	Description string           `xml:"description"`
+	NumPeriods  int              `xml:"num-periods"`
+	Recurrence  types.Recurrence `xml:"recurrence"`
+	Slots       Slots            `xml:"slots"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// Amount returns the amount budgeted for the account in the given period.
+// It returns nil if no amount is set.
+func (b *Budget) Amount(acc *Account, period int) *types.Numeric {
+	return b.Slots.GetNumeric(string(acc.ID) + "/" + strconv.Itoa(period))
+}
+
+// PeriodStart returns the first day of the given period (0 based).
+func (b *Budget) PeriodStart(period int) time.Time {
+	// as recurrenceNthInstance in GnuCash
+	r := &b.Recurrence
+	d := r.Next(time.Time{})
+	for j := 0; j < period; j++ {
+		d = r.Next(d)
+	}
+	return d
+}
+
+// PeriodEnd returns the first day following the given period,
+// i.e. the start of the next period.
+func (b *Budget) PeriodEnd(period int) time.Time {
+	return b.PeriodStart(period + 1)
+}
+
+// Add adds a budget to the collection
+func (bs *Budgets) Add(b *Budget) {
+	*bs = append(*bs, b)
+}
+
+// Len returns the number of budgets.
+func (bs Budgets) Len() int {
+	return len(bs)
+}
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"testing"
+)
+
+const testBudgetBook = `<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Food</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>EXPENSE</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:budget version="2.0.0">
+  <bgt:id type="guid">000000000000000000000000000000b1</bgt:id>
+  <bgt:name>2016</bgt:name>
+  <bgt:description>Family budget</bgt:description>
+  <bgt:num-periods>12</bgt:num-periods>
+  <bgt:recurrence version="1.0.0">
+    <recurrence:mult>1</recurrence:mult>
+    <recurrence:period_type>month</recurrence:period_type>
+    <recurrence:start><gdate>2016-01-01</gdate></recurrence:start>
+  </bgt:recurrence>
+  <bgt:slots>
+    <slot>
+      <slot:key>000000000000000000000000000000c1</slot:key>
+      <slot:value type="frame">
+        <slot>
+          <slot:key>0</slot:key>
+          <slot:value type="numeric">40000/100</slot:value>
+        </slot>
+        <slot>
+          <slot:key>1</slot:key>
+          <slot:value type="numeric">35000/100</slot:value>
+        </slot>
+      </slot:value>
+    </slot>
+  </bgt:slots>
+</gnc:budget>
+`
+
+func TestBudget(t *testing.T) {
+	book := readTestBook(t, testBudgetBook)
+
+	if book.Budgets.Len() != 1 {
+		t.Fatalf("Budgets: expected 1, got %d", book.Budgets.Len())
+	}
+	bgt := book.Budgets[0]
+	if bgt.Name != "2016" || bgt.Description != "Family budget" || bgt.NumPeriods != 12 {
+		t.Errorf("Budget: unexpected values %+v", bgt)
+	}
+
+	food := book.Accounts.List[1]
+	var testCases = []struct {
+		period int
+		amount string
+		start  string
+		end    string
+	}{
+		{0, "40000/100", "2016-01-01", "2016-02-01"},
+		{1, "35000/100", "2016-02-01", "2016-03-01"},
+		{11, "", "2016-12-01", "2017-01-01"},
+	}
+	for _, tc := range testCases {
+		amount := ""
+		if n := bgt.Amount(food, tc.period); n != nil {
+			amount = n.String()
+		}
+		if amount != tc.amount {
+			t.Errorf("Amount(%d): expected %q, got %q", tc.period, tc.amount, amount)
+		}
+		if s := bgt.PeriodStart(tc.period).Format("2006-01-02"); s != tc.start {
+			t.Errorf("PeriodStart(%d): expected %s, got %s", tc.period, tc.start, s)
+		}
+		if s := bgt.PeriodEnd(tc.period).Format("2006-01-02"); s != tc.end {
+			t.Errorf("PeriodEnd(%d): expected %s, got %s", tc.period, tc.end, s)
+		}
+	}
+}
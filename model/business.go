@@ -0,0 +1,377 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+AddressContent = (
+    attribute version { "2.0.0" },
+    ( empty
+    | ( element addr:name { text }?,
+        element addr:addr1 { text }?,
+        element addr:addr2 { text }?,
+        element addr:addr3 { text }?,
+        element addr:addr4 { text }?,
+        element addr:phone { text }?,
+        element addr:fax { text }?,
+        element addr:email { text }?
+      )
+    )
+)
+
+OwnerContent = (
+  attribute version { "2.0.0" },
+  element owner:type { "gncCustomer"
+                     | "gncJob"
+                     | "gncVendor"
+                     | "gncEmployee" },
+  element owner:id { attribute type { "guid" }, GUID }
+)
+*/
+
+// Address type
+type Address struct {
+	Name  string `xml:"name"`
+	Addr1 string `xml:"addr1"`
+	Addr2 string `xml:"addr2"`
+	Addr3 string `xml:"addr3"`
+	Addr4 string `xml:"addr4"`
+	Phone string `xml:"phone"`
+	Fax   string `xml:"fax"`
+	Email string `xml:"email"`
+}
+
+// Lines returns the not empty lines of the address: name and addr1-4.
+func (a *Address) Lines() []string {
+	var lines []string
+	for _, s := range []string{a.Name, a.Addr1, a.Addr2, a.Addr3, a.Addr4} {
+		if s != "" {
+			lines = append(lines, s)
+		}
+	}
+	return lines
+}
+
+// OwnerType enum type
+type OwnerType string
+
+// OwnerType constants
+const (
+	OwnerTypeCustomer OwnerType = "gncCustomer"
+	OwnerTypeJob      OwnerType = "gncJob"
+	OwnerTypeVendor   OwnerType = "gncVendor"
+	OwnerTypeEmployee OwnerType = "gncEmployee"
+)
+
+// Owner type is a reference to a customer, job, vendor or employee.
+// Only the field corresponding to Type is set, once resolved.
+type Owner struct {
+	Type OwnerType  `xml:"type"`
+	ID   types.GUID `xml:"id"`
+
+	Customer *Customer `xml:"-"`
+	Job      *Job      `xml:"-"`
+	Vendor   *Vendor   `xml:"-"`
+	Employee *Employee `xml:"-"`
+}
+
+// Name returns the name of the owner.
+func (o *Owner) Name() string {
+	switch {
+	case o.Customer != nil:
+		return o.Customer.Name
+	case o.Job != nil:
+		return o.Job.Name
+	case o.Vendor != nil:
+		return o.Vendor.Name
+	case o.Employee != nil:
+		return o.Employee.Addr.Name
+	}
+	return ""
+}
+
+// End returns the final owner: the owner of the job in case of a job,
+// the owner itself otherwise.
+func (o *Owner) End() *Owner {
+	if o.Job != nil {
+		return &o.Job.Owner
+	}
+	return o
+}
+
+// Business type holds the objects of the business features.
+type Business struct {
+	Customers []*Customer
+	Vendors   []*Vendor
+	Employees []*Employee
+	Jobs      []*Job
+	Invoices  []*Invoice
+	Entries   []*Entry
+	Orders    []*Order
+	BillTerms []*BillTerm
+	TaxTables []*TaxTable
+
+	customerMap map[types.GUID]*Customer
+	vendorMap   map[types.GUID]*Vendor
+	employeeMap map[types.GUID]*Employee
+	jobMap      map[types.GUID]*Job
+	invoiceMap  map[types.GUID]*Invoice
+	orderMap    map[types.GUID]*Order
+	billTermMap map[types.GUID]*BillTerm
+	taxTableMap map[types.GUID]*TaxTable
+	// lotInvoiceMap maps the post lots to their invoice.
+	lotInvoiceMap map[*Lot]*Invoice
+}
+
+// Customer returns the customer with the given GUID, or nil.
+func (b *Business) Customer(id types.GUID) *Customer {
+	return b.customerMap[id]
+}
+
+// Vendor returns the vendor with the given GUID, or nil.
+func (b *Business) Vendor(id types.GUID) *Vendor {
+	return b.vendorMap[id]
+}
+
+// Employee returns the employee with the given GUID, or nil.
+func (b *Business) Employee(id types.GUID) *Employee {
+	return b.employeeMap[id]
+}
+
+// Job returns the job with the given GUID, or nil.
+func (b *Business) Job(id types.GUID) *Job {
+	return b.jobMap[id]
+}
+
+// Invoice returns the invoice with the given GUID, or nil.
+func (b *Business) Invoice(id types.GUID) *Invoice {
+	return b.invoiceMap[id]
+}
+
+// Order returns the order with the given GUID, or nil.
+func (b *Business) Order(id types.GUID) *Order {
+	return b.orderMap[id]
+}
+
+// BillTerm returns the bill term with the given GUID, or nil.
+func (b *Business) BillTerm(id types.GUID) *BillTerm {
+	return b.billTermMap[id]
+}
+
+// TaxTable returns the tax table with the given GUID, or nil.
+func (b *Business) TaxTable(id types.GUID) *TaxTable {
+	return b.taxTableMap[id]
+}
+
+// Len returns the number of business objects.
+func (b *Business) Len() int {
+	return len(b.Customers) + len(b.Vendors) + len(b.Employees) + len(b.Jobs) +
+		len(b.Invoices) + len(b.Entries) + len(b.Orders) + len(b.BillTerms) + len(b.TaxTables)
+}
+
+// resolveOwner sets the object referenced by the owner.
+func (b *Business) resolveOwner(o *Owner) error {
+	var found bool
+	switch o.Type {
+	case OwnerTypeCustomer:
+		o.Customer = b.Customer(o.ID)
+		found = o.Customer != nil
+	case OwnerTypeJob:
+		o.Job = b.Job(o.ID)
+		found = o.Job != nil
+	case OwnerTypeVendor:
+		o.Vendor = b.Vendor(o.ID)
+		found = o.Vendor != nil
+	case OwnerTypeEmployee:
+		o.Employee = b.Employee(o.ID)
+		found = o.Employee != nil
+	default:
+		return fmt.Errorf("Invalid owner type: %q", o.Type)
+	}
+	if !found {
+		return fmt.Errorf("Owner not found: type=%s, id=%s", o.Type, o.ID)
+	}
+	return nil
+}
+
+// link indexes the business objects and resolves their references
+// to each other and to the book objects. It returns an error for every
+// reference not found, linking all the other references anyway.
+func (b *Business) link(book *Book) []error {
+	var errs []error
+
+	b.billTermMap = map[types.GUID]*BillTerm{}
+	for _, bt := range b.BillTerms {
+		b.billTermMap[bt.ID] = bt
+	}
+	b.taxTableMap = map[types.GUID]*TaxTable{}
+	for _, tt := range b.TaxTables {
+		b.taxTableMap[tt.ID] = tt
+		for _, tte := range tt.Entries {
+			if tte.AccountID != "" {
+				tte.Account = book.AccountMap[tte.AccountID]
+			}
+		}
+	}
+
+	b.customerMap = map[types.GUID]*Customer{}
+	for _, c := range b.Customers {
+		b.customerMap[c.ID] = c
+		c.Currency = book.Commodities.Lookup(c.Currency)
+		c.Terms = b.BillTerm(c.TermsID)
+		c.TaxTable = b.TaxTable(c.TaxTableID)
+	}
+	b.vendorMap = map[types.GUID]*Vendor{}
+	for _, v := range b.Vendors {
+		b.vendorMap[v.ID] = v
+		v.Currency = book.Commodities.Lookup(v.Currency)
+		v.Terms = b.BillTerm(v.TermsID)
+		v.TaxTable = b.TaxTable(v.TaxTableID)
+	}
+	b.employeeMap = map[types.GUID]*Employee{}
+	for _, e := range b.Employees {
+		b.employeeMap[e.ID] = e
+		e.Currency = book.Commodities.Lookup(e.Currency)
+		if e.CCardID != "" {
+			e.CCard = book.AccountMap[e.CCardID]
+		}
+	}
+	b.jobMap = map[types.GUID]*Job{}
+	for _, j := range b.Jobs {
+		b.jobMap[j.ID] = j
+	}
+
+	for _, j := range b.Jobs {
+		if err := b.resolveOwner(&j.Owner); err != nil {
+			errs = append(errs, fmt.Errorf("Job %s: %s", j.Number, err))
+		}
+		switch {
+		case j.Owner.Customer != nil:
+			j.Owner.Customer.Jobs = append(j.Owner.Customer.Jobs, j)
+		case j.Owner.Vendor != nil:
+			j.Owner.Vendor.Jobs = append(j.Owner.Vendor.Jobs, j)
+		}
+	}
+
+	b.invoiceMap = map[types.GUID]*Invoice{}
+	b.lotInvoiceMap = map[*Lot]*Invoice{}
+	for _, inv := range b.Invoices {
+		b.invoiceMap[inv.ID] = inv
+		if err := b.resolveOwner(&inv.Owner); err != nil {
+			errs = append(errs, fmt.Errorf("Invoice %s: %s", inv.Number, err))
+		}
+		if inv.BillTo != nil && inv.BillTo.ID != "" {
+			if err := b.resolveOwner(inv.BillTo); err != nil {
+				errs = append(errs, fmt.Errorf("Invoice %s: billto: %s", inv.Number, err))
+			}
+		}
+		inv.Currency = book.Commodities.Lookup(inv.Currency)
+		inv.Terms = b.BillTerm(inv.TermsID)
+		if inv.PostTxnID != "" {
+			if inv.PostTxn = book.TransactionMap[inv.PostTxnID]; inv.PostTxn == nil {
+				errs = append(errs, fmt.Errorf("Invoice %s: post transaction not found: %s", inv.Number, inv.PostTxnID))
+			}
+		}
+		if inv.PostLotID != "" {
+			if inv.PostLot = book.LotMap[inv.PostLotID]; inv.PostLot == nil {
+				errs = append(errs, fmt.Errorf("Invoice %s: post lot not found: %s", inv.Number, inv.PostLotID))
+			} else {
+				b.lotInvoiceMap[inv.PostLot] = inv
+			}
+		}
+		if inv.PostAccID != "" {
+			if inv.PostAccount = book.AccountMap[inv.PostAccID]; inv.PostAccount == nil {
+				errs = append(errs, fmt.Errorf("Invoice %s: post account not found: %s", inv.Number, inv.PostAccID))
+			}
+		}
+	}
+	b.orderMap = map[types.GUID]*Order{}
+	for _, o := range b.Orders {
+		b.orderMap[o.ID] = o
+		if err := b.resolveOwner(&o.Owner); err != nil {
+			errs = append(errs, fmt.Errorf("Order %s: %s", o.Number, err))
+		}
+	}
+
+	for _, e := range b.Entries {
+		e.InvAccount = book.AccountMap[e.InvAccountID]
+		e.InvTaxTable = b.TaxTable(e.InvTaxTableID)
+		e.BillAccount = book.AccountMap[e.BillAccountID]
+		e.BillTaxTable = b.TaxTable(e.BillTaxTableID)
+		if e.InvoiceID != "" {
+			if e.Invoice = b.Invoice(e.InvoiceID); e.Invoice == nil {
+				errs = append(errs, fmt.Errorf("Entry %s: invoice not found: %s", e.ID, e.InvoiceID))
+			} else {
+				e.Invoice.Entries = append(e.Invoice.Entries, e)
+			}
+		}
+		if e.BillID != "" {
+			if e.Bill = b.Invoice(e.BillID); e.Bill == nil {
+				errs = append(errs, fmt.Errorf("Entry %s: bill not found: %s", e.ID, e.BillID))
+			} else {
+				e.Bill.Entries = append(e.Bill.Entries, e)
+			}
+		}
+		if e.BillTo != nil && e.BillTo.ID != "" {
+			if err := b.resolveOwner(e.BillTo); err != nil {
+				errs = append(errs, fmt.Errorf("Entry %s: billto: %s", e.ID, err))
+			}
+		}
+		if e.OrderID != "" {
+			if e.Order = b.Order(e.OrderID); e.Order == nil {
+				errs = append(errs, fmt.Errorf("Entry %s: order not found: %s", e.ID, e.OrderID))
+			} else {
+				e.Order.Entries = append(e.Order.Entries, e)
+			}
+		}
+	}
+
+	return errs
+}
+
+// OwnerInvoices returns the invoices whose end owner is the given
+// customer, vendor or employee, including the invoices of its jobs.
+func (b *Business) OwnerInvoices(id types.GUID) []*Invoice {
+	var list []*Invoice
+	for _, inv := range b.Invoices {
+		if inv.Owner.End().ID == id {
+			list = append(list, inv)
+		}
+	}
+	return list
+}
+
+// LotInvoice returns the invoice posted to the lot, or nil.
+func (b *Business) LotInvoice(lot *Lot) *Invoice {
+	return b.lotInvoiceMap[lot]
+}
+
+// ownerTypes maps the GncOwnerType values stored in the lot slots.
+var ownerTypes = map[int64]OwnerType{
+	2: OwnerTypeCustomer,
+	3: OwnerTypeJob,
+	4: OwnerTypeVendor,
+	5: OwnerTypeEmployee,
+}
+
+// LotOwner returns the end owner of a business lot: the owner of its
+// invoice, or the owner stored in the "gncOwner" slots of a payment lot.
+// It returns nil if the lot has no owner.
+func (b *Business) LotOwner(lot *Lot) *Owner {
+	if inv := b.LotInvoice(lot); inv != nil {
+		return inv.Owner.End()
+	}
+	id := lot.Slots.GetGUID("gncOwner/owner-guid")
+	v := lot.Slots.Get("gncOwner/owner-type")
+	if id == "" || v == nil || v.Type != KvpTypeInteger {
+		return nil
+	}
+	o := &Owner{Type: ownerTypes[v.Integer], ID: id}
+	if b.resolveOwner(o) != nil {
+		return nil
+	}
+	return o.End()
+}
@@ -0,0 +1,125 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testBusinessBook = `<gnc:GncCustomer version="2.0.0">
+  <cust:guid type="guid">000000000000000000000000000000a1</cust:guid>
+  <cust:name>Acme Srl</cust:name>
+  <cust:id>000001</cust:id>
+  <cust:addr version="2.0.0">
+    <addr:name>Acme Srl</addr:name>
+    <addr:addr1>Via Roma 1</addr:addr1>
+    <addr:addr2>00100 Roma</addr:addr2>
+    <addr:email>info@acme.example</addr:email>
+  </cust:addr>
+  <cust:shipaddr version="2.0.0"/>
+  <cust:notes>good customer</cust:notes>
+  <cust:taxincluded>USEGLOBAL</cust:taxincluded>
+  <cust:active>1</cust:active>
+  <cust:discount>0/1</cust:discount>
+  <cust:credit>500000/100</cust:credit>
+  <cust:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></cust:currency>
+  <cust:use-tt>0</cust:use-tt>
+</gnc:GncCustomer>
+<gnc:GncEmployee version="2.0.0">
+  <employee:guid type="guid">000000000000000000000000000000a2</employee:guid>
+  <employee:username>mario</employee:username>
+  <employee:id>000001</employee:id>
+  <employee:addr version="2.0.0">
+    <addr:name>Mario Rossi</addr:name>
+  </employee:addr>
+  <employee:active>1</employee:active>
+  <employee:workday>8/1</employee:workday>
+  <employee:rate>2500/100</employee:rate>
+  <employee:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></employee:currency>
+</gnc:GncEmployee>
+<gnc:GncJob version="2.0.0">
+  <job:guid type="guid">000000000000000000000000000000b1</job:guid>
+  <job:id>000001</job:id>
+  <job:name>Website</job:name>
+  <job:reference>PO-42</job:reference>
+  <job:owner version="2.0.0">
+    <owner:type>gncCustomer</owner:type>
+    <owner:id type="guid">000000000000000000000000000000a1</owner:id>
+  </job:owner>
+  <job:active>1</job:active>
+</gnc:GncJob>
+<gnc:GncJob version="2.0.0">
+  <job:guid type="guid">000000000000000000000000000000b2</job:guid>
+  <job:id>000002</job:id>
+  <job:name>Hosting</job:name>
+  <job:owner version="2.0.0">
+    <owner:type>gncVendor</owner:type>
+    <owner:id type="guid">000000000000000000000000000000a3</owner:id>
+  </job:owner>
+  <job:active>0</job:active>
+</gnc:GncJob>
+<gnc:GncVendor version="2.0.0">
+  <vendor:guid type="guid">000000000000000000000000000000a3</vendor:guid>
+  <vendor:name>Hosting Inc</vendor:name>
+  <vendor:id>000001</vendor:id>
+  <vendor:addr version="2.0.0"/>
+  <vendor:taxincluded>NO</vendor:taxincluded>
+  <vendor:active>1</vendor:active>
+  <vendor:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></vendor:currency>
+  <vendor:use-tt>0</vendor:use-tt>
+</gnc:GncVendor>
+`
+
+func TestBusiness(t *testing.T) {
+	book := readTestBook(t, testBusinessBook)
+	biz := &book.Business
+
+	if biz.Len() != 5 {
+		t.Fatalf("Business: expected 5 objects, got %d", biz.Len())
+	}
+
+	cust := biz.Customer("000000000000000000000000000000a1")
+	if cust == nil {
+		t.Fatalf("Customer: not found")
+	}
+	if cust.Name != "Acme Srl" || cust.Number != "000001" || !cust.Active || cust.Notes != "good customer" {
+		t.Errorf("Customer: unexpected values %+v", cust)
+	}
+	if cust.Credit.String() != "500000/100" {
+		t.Errorf("Customer.Credit: got %s", cust.Credit)
+	}
+	if cust.Currency != book.Commodities.Get("ISO4217", "EUR") {
+		t.Errorf("Customer.Currency: not resolved")
+	}
+	expected := []string{"Acme Srl", "Via Roma 1", "00100 Roma"}
+	if lines := cust.Addr.Lines(); !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Customer.Addr.Lines: expected %v, got %v", expected, lines)
+	}
+	if cust.Addr.Email != "info@acme.example" {
+		t.Errorf("Customer.Addr.Email: got %q", cust.Addr.Email)
+	}
+
+	emp := biz.Employee("000000000000000000000000000000a2")
+	if emp == nil || emp.Username != "mario" || emp.Rate.String() != "2500/100" {
+		t.Errorf("Employee: unexpected value %+v", emp)
+	}
+
+	vendor := biz.Vendor("000000000000000000000000000000a3")
+	if vendor == nil || vendor.Name != "Hosting Inc" {
+		t.Fatalf("Vendor: unexpected value %+v", vendor)
+	}
+
+	web := biz.Job("000000000000000000000000000000b1")
+	hosting := biz.Job("000000000000000000000000000000b2")
+	if web.Owner.Customer != cust || web.Owner.Name() != "Acme Srl" || !web.Active {
+		t.Errorf("Job %s: unexpected owner %+v", web.Name, web.Owner)
+	}
+	if hosting.Owner.Vendor != vendor || hosting.Active {
+		t.Errorf("Job %s: unexpected owner %+v", hosting.Name, hosting.Owner)
+	}
+	if len(cust.Jobs) != 1 || cust.Jobs[0] != web {
+		t.Errorf("Customer.Jobs: unexpected jobs %v", cust.Jobs)
+	}
+	if len(vendor.Jobs) != 1 || vendor.Jobs[0] != hosting {
+		t.Errorf("Vendor.Jobs: unexpected jobs %v", vendor.Jobs)
+	}
+}
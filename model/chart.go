@@ -0,0 +1,238 @@
+package model
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+/*
+An accounts-only file is the second form of GnuCashXml in doc/gnucash-v2.rnc:
+
+	<gnc-v2>
+	  <gnc:count-data cd:type="commodity">
+	  <gnc:count-data cd:type="account">
+	  <gnc:commodity>
+	  <gnc:account>
+
+The account templates shipped by GnuCash (.gnucash-xea files) have
+a gnc-account-example root, with a title and the descriptions:
+
+	<gnc-account-example>
+	  <gnc-act:title>
+	  <gnc-act:short-description>
+	  <gnc-act:long-description>
+	  <gnc:account>
+*/
+
+// Chart type is the account chart of an accounts-only file,
+// as the account templates of GnuCash.
+type Chart struct {
+	// Title and the descriptions are set by the account templates.
+	Title            string
+	ShortDescription string
+	LongDescription  string
+
+	Commodities Commodities
+	Accounts    Accounts
+	AccountMap  AccountMap
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// isChartRoot returns true if name is the root element of an
+// account template.
+func isChartRoot(name xml.Name) bool {
+	return name.Local == "gnc-account-example"
+}
+
+// chartObject reads the child element of the chart started by se.
+func (r *bookReader) chartObject(decoder *xml.Decoder, se xml.StartElement) error {
+	chart := r.chart
+
+	var text *string
+	switch se.Name.Local {
+	case "count-data":
+		// the counters are computed by the writer
+		return decoder.Skip()
+	case "title":
+		text = &chart.Title
+	case "short-description":
+		text = &chart.ShortDescription
+	case "long-description":
+		text = &chart.LongDescription
+	case "commodity":
+		var cmdty Commodity
+		if err := decoder.DecodeElement(&cmdty, &se); err != nil {
+			return err
+		}
+		chart.Commodities.Add(&cmdty)
+	case "account":
+		account, parentID, err := AccountUnmarshalXML(decoder, chart.Commodities)
+		if err != nil {
+			return err
+		}
+		account.addAttrs(se.Attr)
+		return chart.Accounts.insert(account, parentID, chart.AccountMap)
+	default:
+		return chart.addElement(decoder, &se)
+	}
+	if text != nil {
+		if err := decoder.DecodeElement(text, &se); err != nil {
+			return err
+		}
+		*text = strings.TrimSpace(*text)
+	}
+	return nil
+}
+
+// Accounts returns the accounts of the book, or of the chart of an
+// accounts-only file. An error is returned if there are neither.
+func (g *Gnc) Accounts() (*Accounts, error) {
+	switch {
+	case g.Chart != nil:
+		return &g.Chart.Accounts, nil
+	case g.Book != nil:
+		return &g.Book.Accounts, nil
+	}
+	return nil, errors.New("No accounts: neither a book nor an account chart")
+}
+
+// resolveAccounts links the accounts of the chart to their parents.
+// An error is returned for every orphan account.
+func (c *Chart) resolveAccounts() []error {
+	c.Accounts.setRoot(c.Accounts.resolve(c.AccountMap))
+	var errs []error
+	for _, acc := range c.Accounts.Orphans {
+		errs = append(errs, orphanError(acc, c.AccountMap))
+	}
+	return errs
+}
+
+// templateBackend type is the backend of the account templates, the XML
+// files with a gnc-account-example root. The xml backend reads them too.
+type templateBackend struct{}
+
+// Format returns "accounts-template".
+func (templateBackend) Format() string {
+	return "accounts-template"
+}
+
+// Capabilities implements Backend interface.
+func (templateBackend) Capabilities() Capabilities {
+	return CanRead | CanWrite
+}
+
+// Detect implements Backend interface.
+func (templateBackend) Detect(header []byte) bool {
+	return bytes.Contains(header, []byte("<gnc-account-example"))
+}
+
+// Load implements Backend interface.
+func (templateBackend) Load(path string, opts *ReadOptions) (*Gnc, error) {
+	gnc, err := xmlBackend{}.Load(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !isChartRoot(gnc.XMLName) {
+		return nil, fmt.Errorf("Not an account template: root element is <%s>", gnc.XMLName.Local)
+	}
+	return gnc, nil
+}
+
+// Save implements Saver interface. The chart is written as an account
+// template, even if read from an accounts-only gnc-v2 file.
+func (templateBackend) Save(path string, gnc *Gnc) error {
+	if gnc.Book != nil || gnc.Chart == nil {
+		return errors.New("Only an account chart can be written as an account template")
+	}
+	t := *gnc
+	t.XMLName = xml.Name{Local: "gnc-account-example"}
+	return WriteFile(path, &t, &WriteOptions{Plain: true})
+}
+
+// AccountDiff type is a difference between two account trees, for the
+// accounts with the same path of names below the root.
+type AccountDiff struct {
+	// Path are the names of the account and of its ancestors, below
+	// the root, separated by "/".
+	Path string
+	// A and B are the accounts of the two trees: one of them is nil
+	// if the account is missing in its tree.
+	A, B *Account
+}
+
+func (d *AccountDiff) String() string {
+	switch {
+	case d.B == nil:
+		return "- " + d.Path
+	case d.A == nil:
+		return "+ " + d.Path
+	}
+	var diffs []string
+	if d.A.Type != d.B.Type {
+		diffs = append(diffs, fmt.Sprintf("type %s -> %s", d.A.Type, d.B.Type))
+	}
+	if !d.A.Currency.Is(d.B.Currency) {
+		diffs = append(diffs, fmt.Sprintf("commodity %s -> %s", d.A.Currency, d.B.Currency))
+	}
+	if d.A.Placeholder() != d.B.Placeholder() {
+		diffs = append(diffs, fmt.Sprintf("placeholder %t -> %t", d.A.Placeholder(), d.B.Placeholder()))
+	}
+	return "~ " + d.Path + ": " + strings.Join(diffs, ", ")
+}
+
+// CompareAccounts compares the account trees a and b, as the chart of an
+// account template and the accounts of a book. The accounts are matched
+// by their path of names below the root. The differences are the
+// accounts of a missing in b, in the order of the tree of a, then the
+// accounts of b missing in a, then the accounts of both with a different
+// type, commodity or placeholder flag. The orphans are not compared.
+func CompareAccounts(a, b *Accounts) []*AccountDiff {
+	pathsA, listA := accountPaths(a)
+	pathsB, listB := accountPaths(b)
+
+	var missing, added, changed []*AccountDiff
+	for _, path := range listA {
+		accA := pathsA[path]
+		accB, ok := pathsB[path]
+		switch {
+		case !ok:
+			missing = append(missing, &AccountDiff{Path: path, A: accA})
+		case accA.Type != accB.Type || !accA.Currency.Is(accB.Currency) || accA.Placeholder() != accB.Placeholder():
+			changed = append(changed, &AccountDiff{Path: path, A: accA, B: accB})
+		}
+	}
+	for _, path := range listB {
+		if _, ok := pathsA[path]; !ok {
+			added = append(added, &AccountDiff{Path: path, B: pathsB[path]})
+		}
+	}
+	return append(append(missing, added...), changed...)
+}
+
+// accountPaths returns the accounts of the tree by their path of names
+// below the root, and the paths in depth first order.
+func accountPaths(accounts *Accounts) (map[string]*Account, []string) {
+	m := map[string]*Account{}
+	var list []string
+	if accounts == nil || accounts.Root == nil {
+		return m, list
+	}
+	var walk func(a *Account, prefix string)
+	walk = func(a *Account, prefix string) {
+		for _, child := range a.Children {
+			path := prefix + child.Name
+			if _, ok := m[path]; !ok {
+				list = append(list, path)
+				m[path] = child
+			}
+			walk(child, path+"/")
+		}
+	}
+	walk(accounts.Root, "")
+	return m, list
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testChartTemplate is an account template as shipped by GnuCash.
+const testChartTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<gnc-account-example
+  xmlns="http://www.gnucash.org/XML/"
+  xmlns:act="http://www.gnucash.org/XML/act"
+  xmlns:cmdty="http://www.gnucash.org/XML/cmdty"
+  xmlns:gnc="http://www.gnucash.org/XML/gnc"
+  xmlns:gnc-act="http://www.gnucash.org/XML/gnc-act"
+  xmlns:slot="http://www.gnucash.org/XML/slot">
+    <gnc-act:title>
+      Common Accounts
+    </gnc-act:title>
+    <gnc-act:short-description>
+      Accounts that most people will need.
+    </gnc-act:short-description>
+    <gnc-act:long-description>
+    Most users will want the bank and the expense accounts.
+    </gnc-act:long-description>
+<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Salary</act:name>
+  <act:id type="guid">000000000000000000000000000000c2</act:id>
+  <act:type>EXPENSE</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Groceries</act:name>
+  <act:id type="guid">000000000000000000000000000000c3</act:id>
+  <act:type>EXPENSE</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+</gnc-account-example>
+`
+
+func TestReadChart(t *testing.T) {
+	gnc, err := Read(strings.NewReader(testChartTemplate))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if gnc.Book != nil || gnc.Chart == nil {
+		t.Fatalf("expected a chart and no book")
+	}
+	chart := gnc.Chart
+	if chart.Title != "Common Accounts" || chart.ShortDescription != "Accounts that most people will need." {
+		t.Errorf("Title: got %q, %q", chart.Title, chart.ShortDescription)
+	}
+	if root := chart.Accounts.Root; root == nil || len(root.Children) != 3 {
+		t.Fatalf("Root: got %v", root)
+	}
+	// the commodities are not declared by the templates
+	bank := chart.AccountMap["000000000000000000000000000000c1"]
+	if bank == nil || bank.Currency == nil || bank.Currency.ID != "EUR" {
+		t.Errorf("Bank: unexpected account %v", bank)
+	}
+
+	// written as an account template, and read back
+	var buf bytes.Buffer
+	if err := Write(&buf, gnc); err != nil {
+		t.Fatalf("Write: unexpected error: %s", err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"\n<gnc-account-example\n",
+		`xmlns:gnc-act="http://www.gnucash.org/XML/gnc-act"`,
+		"<gnc-act:title>Common Accounts</gnc-act:title>",
+		"</gnc-account-example>\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Write: %q not found in\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "count-data") {
+		t.Errorf("Write: unexpected count-data in an account template")
+	}
+	gnc2, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: unexpected error: %s", err)
+	}
+	chart2 := gnc2.Chart
+	if chart2 == nil || chart2.Accounts.Len() != 4 || chart2.Title != chart.Title ||
+		chart2.ShortDescription != chart.ShortDescription || chart2.LongDescription != chart.LongDescription {
+		t.Fatalf("Read: unexpected chart %+v", chart2)
+	}
+	if diffs := CompareAccounts(&chart.Accounts, &chart2.Accounts); len(diffs) != 0 {
+		t.Errorf("Read: unexpected differences %v", diffs)
+	}
+
+	// written as an accounts-only gnc-v2 file, and read back
+	buf.Reset()
+	if err := Write(&buf, &Gnc{Chart: chart}); err != nil {
+		t.Fatalf("Write: unexpected error: %s", err)
+	}
+	if !strings.Contains(buf.String(), `<gnc:count-data cd:type="account">4</gnc:count-data>`) {
+		t.Errorf("Write: missing account count:\n%s", buf.String())
+	}
+	gnc3, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: unexpected error: %s", err)
+	}
+	if gnc3.Chart == nil || gnc3.Chart.Accounts.Len() != 4 || gnc3.Chart.Commodities.Len() != 0 {
+		t.Errorf("Read: unexpected chart %+v", gnc3.Chart)
+	}
+}
+
+func TestCompareAccounts(t *testing.T) {
+	gnc, err := Read(strings.NewReader(testChartTemplate))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	book := readTestBook(t, testTransactionsBook)
+
+	var diffs []string
+	for _, d := range CompareAccounts(&gnc.Chart.Accounts, &book.Accounts) {
+		diffs = append(diffs, d.String())
+	}
+	expected := []string{"- Groceries", "~ Salary: type Expense -> Income"}
+	if strings.Join(diffs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %q, got %q", expected, diffs)
+	}
+
+	if diffs := CompareAccounts(&book.Accounts, &book.Accounts); len(diffs) != 0 {
+		t.Errorf("same accounts: expected no differences, got %v", diffs)
+	}
+}
+
+func TestCompareChartFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "common.gnucash-xea")
+	if err := os.WriteFile(path, []byte(testChartTemplate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gnc, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: unexpected error: %s", err)
+	}
+	chart, err := gnc.Accounts()
+	if err != nil {
+		t.Fatalf("Accounts: unexpected error: %s", err)
+	}
+	book := readTestBook(t, testTransactionsBook)
+	if diffs := CompareAccounts(chart, &book.Accounts); len(diffs) != 2 {
+		t.Errorf("expected 2 differences, got %v", diffs)
+	}
+
+	if _, err := (&Gnc{}).Accounts(); err == nil {
+		t.Errorf("no book and no chart: expected an error")
+	}
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// Severity type is the severity of a Problem.
+type Severity string
+
+// Severity constants
+const (
+	// SeverityError is the severity of the data not consistent with
+	// the GnuCash engine rules.
+	SeverityError Severity = "error"
+	// SeverityWarning is the severity of the data GnuCash accepts,
+	// but that is probably wrong.
+	SeverityWarning Severity = "warning"
+)
+
+// Problem type is an integrity problem of a book found by Check.
+type Problem struct {
+	// Check is the name of the failed check, as "balance".
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	// Object and ID are the type and the GUID of the object with the
+	// problem, if any, as "transaction".
+	Object  string     `json:"object,omitempty"`
+	ID      types.GUID `json:"id,omitempty"`
+	Message string     `json:"message"`
+}
+
+func (p *Problem) String() string {
+	if p.Object == "" {
+		return fmt.Sprintf("%s: %s: %s", p.Severity, p.Check, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s %s: %s", p.Severity, p.Check, p.Object, p.ID, p.Message)
+}
+
+// Check validates the book, returning the problems found:
+//
+//	balance      the values of the splits of a transaction do not sum to zero
+//	quantity     the quantity of a split differs from its value, while the
+//	             commodity of the account is the currency of the transaction
+//	placeholder  a split is in a placeholder account (warning)
+//	commodity    a commodity or currency referenced by an account, a transaction
+//	             or a price is missing, or is not among the book commodities
+//	count        a count-data element differs from the objects read
+//
+// The template transactions of the scheduled transactions are not checked.
+func (b *Book) Check() []*Problem {
+	var problems []*Problem
+	add := func(check string, sev Severity, object string, id types.GUID, format string, args ...interface{}) {
+		problems = append(problems, &Problem{
+			Check:    check,
+			Severity: sev,
+			Object:   object,
+			ID:       id,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// unresolved describes c, a reference of the object, if it is not
+	// a commodity of the book
+	unresolved := func(object string, id types.GUID, what string, c *Commodity) {
+		switch {
+		case c == nil:
+			add("commodity", SeverityError, object, id, "no %s", what)
+		case b.Commodities.Get(c.Space, c.ID) != c:
+			add("commodity", SeverityError, object, id, "%s %s:%s not found", what, c.Space, c.ID)
+		}
+	}
+
+	for _, acc := range b.Accounts.List {
+		if acc.Type != types.AccountTypeRoot {
+			unresolved("account", acc.ID, "commodity", acc.Currency)
+		}
+	}
+
+	for _, t := range b.Transactions {
+		unresolved("transaction", t.ID, "currency", t.Currency)
+		sum := types.New(0, 1)
+		for _, s := range t.Splits {
+			sum.AddEqual(&s.Value)
+			if s.Account == nil {
+				continue
+			}
+			if s.Account.Placeholder() {
+				add("placeholder", SeverityWarning, "split", s.ID, "split in placeholder account %s", s.Account.FullName())
+			}
+			if s.Account.Currency.Is(t.Currency) && !types.Sub(&s.Value, &s.Quantity).IsZero() {
+				add("quantity", SeverityError, "split", s.ID, "quantity %s differs from value %s in %s",
+					s.Quantity.GncString(), s.Value.GncString(), t.Currency)
+			}
+		}
+		if !sum.IsZero() {
+			add("balance", SeverityError, "transaction", t.ID, "splits of %q on %s sum to %s, not zero",
+				t.Description, time.Time(t.DatePosted).Format("2006-01-02"), sum.GncString())
+		}
+	}
+
+	for _, p := range b.PriceDB.Prices {
+		unresolved("price", p.ID, "commodity", p.Commodity)
+		unresolved("price", p.ID, "currency", p.Currency)
+	}
+
+	// count-data types, sorted
+	names := make([]string, 0, len(b.Counts))
+	for typ := range b.Counts {
+		names = append(names, typ)
+	}
+	sort.Strings(names)
+	actual := b.counts()
+	for _, typ := range names {
+		n, ok := actual[typ]
+		if ok && n != b.Counts[typ] {
+			add("count", SeverityError, "", "", "count-data %s is %d, found %d", typ, b.Counts[typ], n)
+		}
+	}
+
+	return problems
+}
+
+// counts returns the number of objects of the book, by count-data type.
+func (b *Book) counts() map[string]int {
+	biz := &b.Business
+	return map[string]int{
+		"commodity":       b.Commodities.Len(),
+		"account":         b.Accounts.Len(),
+		"transaction":     b.Transactions.Len(),
+		"schedxaction":    b.ScheduledTransactions.Len(),
+		"budget":          b.Budgets.Len(),
+		"price":           b.PriceDB.Len(),
+		"gnc:GncBillTerm": len(biz.BillTerms),
+		"gnc:GncCustomer": len(biz.Customers),
+		"gnc:GncEmployee": len(biz.Employees),
+		"gnc:GncEntry":    len(biz.Entries),
+		"gnc:GncInvoice":  len(biz.Invoices),
+		"gnc:GncJob":      len(biz.Jobs),
+		"gnc:GncOrder":    len(biz.Orders),
+		"gnc:GncTaxTable": len(biz.TaxTables),
+		"gnc:GncVendor":   len(biz.Vendors),
+	}
+}
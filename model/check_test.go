@@ -0,0 +1,89 @@
+package model
+
+import (
+	"testing"
+)
+
+const testCheckBook = `<gnc:count-data cd:type="account">4</gnc:count-data>
+<gnc:count-data cd:type="transaction">2</gnc:count-data>
+<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Income</act:name>
+  <act:id type="guid">000000000000000000000000000000c2</act:id>
+  <act:type>INCOME</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:slots>
+    <slot>
+      <slot:key>placeholder</slot:key>
+      <slot:value type="string">true</slot:value>
+    </slot>
+  </act:slots>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Broker</act:name>
+  <act:id type="guid">000000000000000000000000000000c3</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>USD</cmdty:id></act:commodity>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d1</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-01-27 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2016-01-27 10:59:00 +0100</ts:date></trn:date-entered>
+  <trn:description>Salary</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e1</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>100/1</split:value>
+      <split:quantity>90/1</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e2</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-90/1</split:value>
+      <split:quantity>-90/1</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c2</split:account>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+`
+
+func TestBookCheck(t *testing.T) {
+	book := readTestBook(t, testTransactionsBook)
+	if problems := book.Check(); len(problems) != 0 {
+		t.Errorf("expected no problems, got %v", problems)
+	}
+
+	book = readTestBook(t, testCheckBook)
+	expected := []string{
+		"error: commodity: account 000000000000000000000000000000c3: commodity ISO4217:USD not found",
+		"error: quantity: split 000000000000000000000000000000e1: quantity 90/1 differs from value 100/1 in EUR",
+		"warning: placeholder: split 000000000000000000000000000000e2: split in placeholder account Root Account/Income",
+		`error: balance: transaction 000000000000000000000000000000d1: splits of "Salary" on 2016-01-27 sum to 10/1, not zero`,
+		"error: count: count-data transaction is 2, found 1",
+	}
+	problems := book.Check()
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %v", len(expected), problems)
+	}
+	for j, p := range problems {
+		if p.String() != expected[j] {
+			t.Errorf("problem %d: expected %q, got %q", j, expected[j], p)
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package model
 
 import (
-	"encoding/xml"
-	"errors"
+	"strconv"
+
+	"github.com/mmbros/gnucash-viewer/types"
 )
 
 /*
@@ -30,74 +31,71 @@ Commodity = element gnc:commodity {
 type Commodities []*Commodity
 
 type Commodity struct {
-	Space       string `xml:"space"`
-	ID          string `xml:"id"`
-	Name        string `xml:"name"`
-	Xcode       string `xml:"xcode"`
-	Fraction    string `xml:"fraction"`
-	GetQuote    string `xml:"get_quote"`
-	QuoteSource string `xml:"quote_source"`
-	QuoteTz     string `xml:"quote_tz"`
+	// GUID is not stored by the XML backend, where a commodity is
+	// identified by Space and ID: it is set by the backends that do.
+	GUID        types.GUID `xml:"-"`
+	Space       string     `xml:"space"`
+	ID          string     `xml:"id"`
+	Name        string     `xml:"name"`
+	Xcode       string     `xml:"xcode"`
+	Fraction    string     `xml:"fraction"`
+	GetQuotes   Flag       `xml:"get_quotes"`
+	QuoteSource string     `xml:"quote_source"`
+	QuoteTz     *string    `xml:"quote_tz"` // nil if missing, empty for the local time zone
+	Slots       Slots      `xml:"slots"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
 }
 
 func (c *Commodity) String() string {
 	return c.ID
 }
 
-func CommodityUnmarshalXML(decoder *xml.Decoder, start xml.StartElement) (*Commodity, error) {
-	// http://stackoverflow.com/questions/17301149/golang-xml-unmarshal-and-time-time-fields
-	// http://blog.davidsingleton.org/parsing-huge-xml-files-with-go/
+// isoMinorUnits are the ISO 4217 minor units of the currencies
+// with other than 2 decimals.
+var isoMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0,
+	"KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0,
+	"VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
 
-	cmdty := Commodity{}
-	var foundID, foundSpace bool
+// Decimals returns the number of decimal digits of the smallest fraction
+// of the commodity, e.g. 2 for a fraction of 100.
+func (c *Commodity) Decimals() int {
+	d := 0
+	for f := c.SCU(); f >= 10; f /= 10 {
+		d++
+	}
+	return d
+}
 
-LOOP:
-	for {
-		// Read tokens from the XML document in a stream.
-		t, _ := decoder.Token()
-		if t == nil {
-			break LOOP
-		}
-		// Inspect the type of the token just read.
-		switch se := t.(type) {
-		case xml.StartElement:
-			var ps *string
-
-			switch se.Name.Local {
-			case "space":
-				ps = &cmdty.Space
-				foundSpace = true
-			case "id":
-				ps = &cmdty.ID
-				foundID = true
-			case "name":
-				ps = &cmdty.Name
-			case "xcode":
-				ps = &cmdty.Xcode
-			case "fraction":
-				ps = &cmdty.Fraction
-			case "get_quote":
-				ps = &cmdty.GetQuote
-			case "quote_source":
-				ps = &cmdty.QuoteSource
-			case "quote_tz":
-				ps = &cmdty.QuoteTz
-			}
-
-			if ps != nil {
-				decoder.DecodeElement(ps, &se)
-			}
-		case xml.EndElement:
-			if se.Name.Local == "commodity" {
-				break LOOP
-			}
-		}
+// SCU returns the smallest commodity unit, as the denominator of the
+// smallest fraction of the commodity, e.g. 100 for cents.
+// The XML backend stores currencies without fraction: the ISO 4217
+// minor units are used, else 100 is assumed.
+func (c *Commodity) SCU() int64 {
+	if c == nil {
+		return 100
 	}
-	if !(foundID && foundSpace) {
-		return nil, errors.New("Commodity without ID and Space values")
+	if f, err := strconv.ParseInt(c.Fraction, 10, 64); err == nil && f > 0 {
+		return f
+	}
+	if d, ok := isoMinorUnits[c.ID]; ok && c.Space == "ISO4217" {
+		f := int64(1)
+		for ; d > 0; d-- {
+			f *= 10
+		}
+		return f
 	}
+	return 100
+}
 
-	return &cmdty, nil
+// FormatAmount returns the amount formatted with the decimals of the commodity.
+func (c *Commodity) FormatAmount(n *types.Numeric) string {
+	return strconv.FormatFloat(n.Float64(), 'f', c.Decimals(), 64)
 }
 
 // Get returns the Commodity identified by space and id
@@ -111,6 +109,27 @@ func (cs Commodities) Get(space, id string) *Commodity {
 	return nil
 }
 
+// Lookup returns the Commodity of the collection with the same space and id
+// of cmdty. In case the commodity is not in the collection, cmdty itself is
+// returned, so that space and id are not lost.
+func (cs Commodities) Lookup(cmdty *Commodity) *Commodity {
+	if cmdty == nil {
+		return nil
+	}
+	if c := cs.Get(cmdty.Space, cmdty.ID); c != nil {
+		return c
+	}
+	return cmdty
+}
+
+// Is returns true if c and x identify the same commodity.
+func (c *Commodity) Is(x *Commodity) bool {
+	if c == nil || x == nil {
+		return c == x
+	}
+	return c.Space == x.Space && c.ID == x.ID
+}
+
 // Add adds a commodity to the collection
 func (cs *Commodities) Add(c *Commodity) {
 	if c.Space == "template" && c.ID == "template" {
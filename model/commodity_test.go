@@ -1 +1,38 @@
 package model
+
+import (
+	"testing"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+func TestCommoditySCU(t *testing.T) {
+	var testCases = []struct {
+		cmdty    *Commodity
+		scu      int64
+		decimals int
+	}{
+		{nil, 100, 2},
+		{&Commodity{Space: "ISO4217", ID: "EUR"}, 100, 2},
+		{&Commodity{Space: "ISO4217", ID: "JPY"}, 1, 0},
+		{&Commodity{Space: "ISO4217", ID: "KWD"}, 1000, 3},
+		{&Commodity{Space: "ISO4217", ID: "JPY", Fraction: "100"}, 100, 2},
+		{&Commodity{Space: "NASDAQ", ID: "JPY"}, 100, 2},
+		{&Commodity{Space: "NASDAQ", ID: "ACME", Fraction: "10000"}, 10000, 4},
+	}
+	for j, tc := range testCases {
+		if actual := tc.cmdty.SCU(); actual != tc.scu {
+			t.Errorf("%d: SCU: expected %d, got %d", j, tc.scu, actual)
+		}
+		if actual := tc.cmdty.Decimals(); actual != tc.decimals {
+			t.Errorf("%d: Decimals: expected %d, got %d", j, tc.decimals, actual)
+		}
+	}
+
+	// 1/3 unit at 1000 KWD, rounded to the fils
+	kwd := &Commodity{Space: "ISO4217", ID: "KWD"}
+	p := &Price{Currency: kwd, Value: *types.New(1000, 1)}
+	if actual := p.Convert(types.New(1, 3)).GncString(); actual != "333333/1000" {
+		t.Errorf("Price.Convert: expected 333333/1000, got %s", actual)
+	}
+}
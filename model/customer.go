@@ -0,0 +1,59 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Customer = element gnc:GncCustomer {
+  attribute version { "2.0.0" },
+  element cust:guid { attribute type { "guid" }, GUID },
+  element cust:name { text },
+  element cust:id { text },
+  element cust:addr { AddressContent },
+  element cust:shipaddr { AddressContent },
+  element cust:notes { text }?,
+  element cust:terms { attribute type { "guid" }, GUID }?,
+  element cust:taxincluded { "YES" | "NO" | "USEGLOBAL"},
+  element cust:active { xsd:boolean { pattern = "[01]" } },
+  element cust:discount { GncNumeric },
+  element cust:credit { GncNumeric },
+  element cust:currency {
+    element cmdty:space { text },
+    element cmdty:id { text }
+  },
+  element cust:use-tt { xsd:boolean { pattern = "[01]" } },
+  element cust:taxtable { attribute type { "guid" }, GUID }?,
+  element cust:slots { KvpSlot+ }?
+}
+*/
+
+// Customer type
+type Customer struct {
+	ID     types.GUID `xml:"guid"`
+	Name   string     `xml:"name"`
+	Number string     `xml:"id"`
+	Addr   Address    `xml:"addr"`
+	// ShipAddr is the shipping address.
+	ShipAddr Address    `xml:"shipaddr"`
+	Notes    string     `xml:"notes"`
+	TermsID  types.GUID `xml:"terms"`
+	// TaxIncluded is one of "YES", "NO", "USEGLOBAL".
+	TaxIncluded string        `xml:"taxincluded"`
+	Active      bool          `xml:"active"`
+	Discount    types.Numeric `xml:"discount"`
+	Credit      types.Numeric `xml:"credit"`
+	Currency    *Commodity    `xml:"currency"`
+	UseTaxTable bool          `xml:"use-tt"`
+	TaxTableID  types.GUID    `xml:"taxtable"`
+	Slots       Slots         `xml:"slots"`
+
+	// Terms and TaxTable are the resolved TermsID and TaxTableID.
+	Terms    *BillTerm `xml:"-"`
+	TaxTable *TaxTable `xml:"-"`
+	// Jobs owned by the customer.
+	Jobs []*Job `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
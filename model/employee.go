@@ -0,0 +1,48 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Employee = element gnc:GncEmployee {
+  attribute version { "2.0.0" },
+  element employee:guid { attribute type { "guid" }, GUID },
+  element employee:username { text },
+  element employee:id { text },
+  element employee:addr { AddressContent },
+  element employee:language { text }?,
+  element employee:acl { text }?,
+  element employee:active { xsd:boolean { pattern = "[01]" } },
+  element employee:workday { GncNumeric },
+  element employee:rate { GncNumeric },
+  element employee:currency {
+    element cmdty:space { text },
+    element cmdty:id { text }
+  },
+  element employee:ccard { attribute type { "guid" }, GUID }?,
+  element employee:slots { KvpSlot+ }?
+}
+*/
+
+// Employee type
+type Employee struct {
+	ID       types.GUID    `xml:"guid"`
+	Username string        `xml:"username"`
+	Number   string        `xml:"id"`
+	Addr     Address       `xml:"addr"`
+	Language string        `xml:"language"`
+	Acl      string        `xml:"acl"`
+	Active   bool          `xml:"active"`
+	Workday  types.Numeric `xml:"workday"`
+	Rate     types.Numeric `xml:"rate"`
+	Currency *Commodity    `xml:"currency"`
+	CCardID  types.GUID    `xml:"ccard"`
+	Slots    Slots         `xml:"slots"`
+
+	// CCard is the credit card account of the employee.
+	CCard *Account `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
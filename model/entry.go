@@ -0,0 +1,205 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Entry = element gnc:GncEntry {
+  attribute version { "2.0.0" },
+  element entry:guid { attribute type { "guid" }, GUID },
+  element entry:date { TimeSpec },
+  element entry:entered { TimeSpec },
+  element entry:description { text }?,
+  element entry:action { text }?,
+  element entry:notes { text }?,
+  element entry:qty { GncNumeric }?,
+  element entry:i-acct { attribute type { "guid" }, GUID }?,
+  element entry:i-price { GncNumeric }?,
+  element entry:i-discount { GncNumeric }?,
+  ( element entry:invoice { attribute type { "guid" }, GUID },
+    element entry:i-disc-type { "VALUE" | "PERCENT" },
+    element entry:i-disc-how { "PRETAX" | "POSTTAX" | "SAMETIME" },
+    element entry:i-taxable { xsd:boolean { pattern = "[01]" } },
+    element entry:i-taxincluded { xsd:boolean { pattern = "[01]" } }
+  )?,
+  element entry:i-taxtable { attribute type { "guid" }, GUID }?,
+  element entry:b-acct { attribute type { "guid" }, GUID }?,
+  element entry:b-price { GncNumeric }?,
+  ( element entry:bill { attribute type { "guid" }, GUID },
+    element entry:billable { xsd:boolean { pattern = "[01]" } },
+    element entry:billto { OwnerContent }?,
+    element entry:b-taxable { xsd:boolean { pattern = "[01]" } },
+    element entry:b-taxincluded { xsd:boolean { pattern = "[01]" } },
+    element entry:b-pay { "CASH" | "CARD" }?
+  )?,
+  element entry:b-taxtable { attribute type { "guid" }, GUID }?,
+  element entry:order { attribute type { "guid" }, GUID }?
+}
+*/
+
+// DiscountHow enum type
+type DiscountHow string
+
+// DiscountHow constants
+const (
+	// DiscountPreTax: the discount is applied before the taxes.
+	DiscountPreTax DiscountHow = "PRETAX"
+	// DiscountSameTime: discount and taxes are both computed on the
+	// undiscounted value.
+	DiscountSameTime DiscountHow = "SAMETIME"
+	// DiscountPostTax: the discount is applied to the value plus taxes.
+	DiscountPostTax DiscountHow = "POSTTAX"
+)
+
+var hundred = types.New(100, 1)
+
+// Entry type is a line of an invoice, a bill or an order.
+// The "i-" fields refer to the customer invoice, the "b-" fields
+// to the vendor bill or employee voucher.
+type Entry struct {
+	ID          types.GUID     `xml:"guid"`
+	Date        types.Timespec `xml:"date"`
+	Entered     types.Timespec `xml:"entered"`
+	Description string         `xml:"description"`
+	Action      string         `xml:"action"`
+	Notes       string         `xml:"notes"`
+	Quantity    types.Numeric  `xml:"qty"`
+
+	InvAccountID    types.GUID    `xml:"i-acct"`
+	InvPrice        types.Numeric `xml:"i-price"`
+	InvDiscount     types.Numeric `xml:"i-discount"`
+	InvoiceID       types.GUID    `xml:"invoice"`
+	InvDiscountType AmountType    `xml:"i-disc-type"`
+	InvDiscountHow  DiscountHow   `xml:"i-disc-how"`
+	InvTaxable      bool          `xml:"i-taxable"`
+	InvTaxIncluded  bool          `xml:"i-taxincluded"`
+	InvTaxTableID   types.GUID    `xml:"i-taxtable"`
+
+	BillAccountID   types.GUID    `xml:"b-acct"`
+	BillPrice       types.Numeric `xml:"b-price"`
+	BillID          types.GUID    `xml:"bill"`
+	Billable        bool          `xml:"billable"`
+	BillTo          *Owner        `xml:"billto"`
+	BillTaxable     bool          `xml:"b-taxable"`
+	BillTaxIncluded bool          `xml:"b-taxincluded"`
+	// BillPayment is one of "CASH", "CARD".
+	BillPayment    string     `xml:"b-pay"`
+	BillTaxTableID types.GUID `xml:"b-taxtable"`
+
+	OrderID types.GUID `xml:"order"`
+
+	// Resolved references.
+	InvAccount   *Account  `xml:"-"`
+	InvTaxTable  *TaxTable `xml:"-"`
+	Invoice      *Invoice  `xml:"-"`
+	BillAccount  *Account  `xml:"-"`
+	BillTaxTable *TaxTable `xml:"-"`
+	Bill         *Invoice  `xml:"-"`
+	Order        *Order    `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// EntryAmounts type holds the amounts computed for an entry.
+type EntryAmounts struct {
+	// Value is the net value, after the discount and without taxes.
+	Value *types.Numeric
+	// Discount is the discount amount.
+	Discount *types.Numeric
+	// Tax is the total of the taxes.
+	Tax *types.Numeric
+}
+
+// Total returns value plus taxes.
+func (ea *EntryAmounts) Total() *types.Numeric {
+	return types.Add(ea.Value, ea.Tax)
+}
+
+// round returns the amounts rounded half up to the denominator den,
+// as gncEntryRecomputeValues does.
+func (ea EntryAmounts) round(den int64) EntryAmounts {
+	return EntryAmounts{
+		Value:    ea.Value.Convert(den, types.RoundHalfUp),
+		Discount: ea.Discount.Convert(den, types.RoundHalfUp),
+		Tax:      ea.Tax.Convert(den, types.RoundHalfUp),
+	}
+}
+
+// denom returns the denominator of the amounts of the entry: the SCU of
+// the currency of its invoice or bill, as gncEntryGetCommodityDenom.
+func (e *Entry) denom() int64 {
+	for _, inv := range []*Invoice{e.Invoice, e.Bill} {
+		if inv != nil && inv.Currency != nil {
+			return inv.Currency.SCU()
+		}
+	}
+	return 100000
+}
+
+// InvoiceAmounts computes the amounts of the entry as a customer invoice line,
+// rounded to the SCU of the currency.
+func (e *Entry) InvoiceAmounts() EntryAmounts {
+	var tt *TaxTable
+	if e.InvTaxable {
+		tt = e.InvTaxTable
+	}
+	return computeEntry(&e.Quantity, &e.InvPrice, tt, e.InvTaxIncluded,
+		&e.InvDiscount, e.InvDiscountType, e.InvDiscountHow).round(e.denom())
+}
+
+// BillAmounts computes the amounts of the entry as a vendor bill
+// or employee voucher line, rounded to the SCU of the currency.
+// Bill lines have no discount.
+func (e *Entry) BillAmounts() EntryAmounts {
+	var tt *TaxTable
+	if e.BillTaxable {
+		tt = e.BillTaxTable
+	}
+	return computeEntry(&e.Quantity, &e.BillPrice, tt, e.BillTaxIncluded,
+		&types.Numeric{}, AmountTypeValue, DiscountPreTax).round(e.denom())
+}
+
+// computeEntry follows gncEntryComputeValue of gncEntry.c, without rounding:
+// the amounts are rounded by the callers.
+func computeEntry(qty, price *types.Numeric, tt *TaxTable, taxIncluded bool,
+	discount *types.Numeric, discType AmountType, discHow DiscountHow) EntryAmounts {
+
+	tvalue, tpercent := tt.rates()
+
+	aggregate := types.Mul(qty, price)
+
+	// the price includes the taxes: remove them to get the pretax value
+	pretax := aggregate
+	if taxIncluded {
+		pretax = types.Div(types.Sub(aggregate, tvalue), types.Add(tpercent, types.New(1, 1)))
+	}
+
+	disc := types.Copy(discount)
+	var result *types.Numeric
+
+	switch discHow {
+	case DiscountPostTax:
+		if discType == AmountTypePercent {
+			afterTax := types.Add(pretax, types.Mul(pretax, tpercent))
+			afterTax.AddEqual(tvalue)
+			disc = types.Mul(afterTax, types.Div(discount, hundred))
+		}
+		result = types.Sub(pretax, disc)
+	default:
+		// PRETAX and SAMETIME: the discount is computed from the pretax value
+		if discType == AmountTypePercent {
+			disc = types.Mul(pretax, types.Div(discount, hundred))
+		}
+		result = types.Sub(pretax, disc)
+		// PRETAX: the taxes are computed on the discounted value
+		if discHow != DiscountSameTime {
+			pretax = result
+		}
+	}
+
+	tax := types.Add(tvalue, types.Mul(pretax, tpercent))
+
+	return EntryAmounts{Value: result, Discount: disc, Tax: tax}
+}
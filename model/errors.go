@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// ParseError type is an error reading a gnucash file in XML format,
+// with the path of the element where it was found and the position
+// of the decoder in the input at that time.
+type ParseError struct {
+	// Path are the qualified names of the elements, from the outermost.
+	Path []string
+	// Line and Column start from 1.
+	Line, Column int
+	// Offset is the byte offset in the uncompressed input.
+	Offset int64
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("line %d, column %d (offset %d): %s", e.Line, e.Column, e.Offset, e.Err)
+	if len(e.Path) == 0 {
+		return msg
+	}
+	return strings.Join(e.Path, "/") + ": " + msg
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parseError returns err as a *ParseError, adding the element name
+// in front of its path. The position is the current position of the
+// decoder, unless err is already a *ParseError.
+func parseError(decoder *xml.Decoder, name xml.Name, err error) error {
+	pe, ok := err.(*ParseError)
+	if !ok {
+		line, column := decoder.InputPos()
+		pe = &ParseError{Line: line, Column: column, Offset: decoder.InputOffset(), Err: err}
+	}
+	if name.Local != "" {
+		pe.Path = append([]string{qualifiedName(name, nil)}, pe.Path...)
+	}
+	return pe
+}
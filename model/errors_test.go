@@ -0,0 +1,158 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testBadTransactionsBook is testTransactionsBook with a transaction
+// having a split in an unknown account, before the good transaction.
+func testBadTransactionsBook() string {
+	j := strings.Index(testTransactionsBook, "<gnc:transaction")
+	bad := strings.NewReplacer(
+		"d1<", "d9<", "e1<", "f1<", "e2<", "f2<", "c2<", "c9<",
+	).Replace(testTransactionsBook[j:])
+	return testTransactionsBook[:j] + bad + testTransactionsBook[j:]
+}
+
+// lineOf returns the line of the first occurrence of substr in s.
+func lineOf(s, substr string) int {
+	return strings.Count(s[:strings.Index(s, substr)], "\n") + 1
+}
+
+func TestReadParseError(t *testing.T) {
+	s := testXMLHeader + testBadTransactionsBook() + testXMLFooter
+	line := lineOf(s, "c9</split:account>")
+	path := "gnc-v2/gnc:book/gnc:transaction/trn:splits/trn:split/split:account"
+
+	_, err := Read(strings.NewReader(s))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("strict: expected a *ParseError, got %v", err)
+	}
+	if p := strings.Join(pe.Path, "/"); p != path {
+		t.Errorf("strict: expected path %s, got %s", path, p)
+	}
+	if pe.Line != line {
+		t.Errorf("strict: expected line %d, got %d", line, pe.Line)
+	}
+	if !strings.HasSuffix(s[:pe.Offset], "c9</split:account>") {
+		t.Errorf("strict: unexpected offset %d", pe.Offset)
+	}
+
+	gnc, err := ReadWithOptions(strings.NewReader(s), &ReadOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if n := gnc.Book.Transactions.Len(); n != 1 || gnc.Book.TransactionMap["000000000000000000000000000000d1"] == nil {
+		t.Errorf("lenient: expected the good transaction only, got %d transactions", n)
+	}
+	if n := gnc.Book.Accounts.Len(); n != 3 {
+		t.Errorf("lenient: expected 3 accounts, got %d", n)
+	}
+	if len(gnc.Warnings) != 1 {
+		t.Fatalf("lenient: expected 1 warning, got %v", gnc.Warnings)
+	}
+	if w, ok := gnc.Warnings[0].(*ParseError); !ok || w.Error() != pe.Error() {
+		t.Errorf("lenient: expected warning %q, got %q", pe, gnc.Warnings[0])
+	}
+}
+
+func TestReadSyntaxError(t *testing.T) {
+	s := testXMLHeader + testTransactionsBook
+	s = s[:strings.LastIndex(s, "</trn:split>")]
+
+	for _, lenient := range []bool{false, true} {
+		_, err := ReadWithOptions(strings.NewReader(s), &ReadOptions{Lenient: lenient})
+		var pe *ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("lenient=%v: expected a *ParseError, got %v", lenient, err)
+		}
+	}
+
+	_, err := Read(strings.NewReader(`<?xml version="1.0"?><html></html>`))
+	if err == nil || !strings.Contains(err.Error(), "root element is <html>") {
+		t.Errorf("not gnucash: unexpected error %v", err)
+	}
+}
+
+func TestReadLinkErrors(t *testing.T) {
+	// the first and the last job have an unknown owner
+	bad := strings.Replace(testBusinessBook, "a1</owner:id>", "a8</owner:id>", 1) + `<gnc:GncJob version="2.0.0">
+  <job:guid type="guid">000000000000000000000000000000b3</job:guid>
+  <job:id>000003</job:id>
+  <job:name>Support</job:name>
+  <job:owner version="2.0.0">
+    <owner:type>gncCustomer</owner:type>
+    <owner:id type="guid">000000000000000000000000000000a9</owner:id>
+  </job:owner>
+  <job:active>1</job:active>
+</gnc:GncJob>
+`
+	s := testXMLHeader + bad + testXMLFooter
+
+	if _, err := Read(strings.NewReader(s)); err == nil || !strings.Contains(err.Error(), "Job 000001") {
+		t.Errorf("strict: expected the error of job 000001, got %v", err)
+	}
+
+	gnc, err := ReadWithOptions(strings.NewReader(s), &ReadOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if len(gnc.Warnings) != 2 ||
+		!strings.Contains(gnc.Warnings[0].Error(), "Job 000001") ||
+		!strings.Contains(gnc.Warnings[1].Error(), "Job 000003") {
+		t.Fatalf("lenient: expected 2 warnings, got %v", gnc.Warnings)
+	}
+	// the references after the first bad one are linked
+	vendor := gnc.Book.Business.Vendor("000000000000000000000000000000a3")
+	if vendor == nil || len(vendor.Jobs) != 1 {
+		t.Errorf("lenient: the job of the vendor is not linked")
+	}
+}
+
+func TestReadLenientSkip(t *testing.T) {
+	// the count is read whole before failing,
+	// the budget fails in the middle of the element
+	bad := `<gnc:count-data cd:type="account">x</gnc:count-data>
+<gnc:budget version="2.0.0">
+  <bgt:id type="guid">000000000000000000000000000000e9</bgt:id>
+  <bgt:num-periods>x</bgt:num-periods>
+  <bgt:name>Bad</bgt:name>
+</gnc:budget>
+`
+	s := testXMLHeader + bad + testTransactionsBook + testXMLFooter
+
+	_, err := Read(strings.NewReader(s))
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("strict: expected a *ParseError, got %v", err)
+	}
+
+	gnc, err := ReadWithOptions(strings.NewReader(s), &ReadOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if len(gnc.Warnings) != 2 {
+		t.Fatalf("lenient: expected 2 warnings, got %v", gnc.Warnings)
+	}
+	if w := gnc.Warnings[0]; w.Error() != pe.Error() {
+		t.Errorf("lenient: expected warning %q, got %q", pe, w)
+	}
+	if w, ok := gnc.Warnings[1].(*ParseError); !ok || w.Line != lineOf(s, "x</bgt:num-periods>") {
+		t.Errorf("lenient: unexpected warning %v", gnc.Warnings[1])
+	}
+	if n := gnc.Book.Budgets.Len(); n != 0 {
+		t.Errorf("lenient: expected no budgets, got %d", n)
+	}
+	if n := len(gnc.Book.UnknownElements); n != 0 {
+		t.Errorf("lenient: the rest of the budget is read as %d book elements", n)
+	}
+	if n := gnc.Book.Accounts.Len(); n != 3 {
+		t.Errorf("lenient: expected 3 accounts, got %d", n)
+	}
+	if n := gnc.Book.Transactions.Len(); n != 1 {
+		t.Errorf("lenient: expected 1 transaction, got %d", n)
+	}
+}
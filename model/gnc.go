@@ -1,8 +1,12 @@
 package model
 
 import (
+	"bufio"
+	"bytes"
 	"compress/gzip"
 	"encoding/xml"
+	"fmt"
+	"io"
 	"os"
 )
 
@@ -63,43 +67,203 @@ type Split struct {
 }
 */
 
+// GnuCash XML namespaces
+const (
+	nsBook   = "http://www.gnucash.org/XML/book"
+	nsGncAct = "http://www.gnucash.org/XML/gnc-act"
+)
+
 // Gnc type
 type Gnc struct {
 	XMLName xml.Name `xml:"gnc-v2"`
 	Book    *Book    `xml:"book"`
+	// Chart is the account chart of an accounts-only file;
+	// it is nil if the file has a book.
+	Chart *Chart `xml:"-"`
+
+	// Attrs are the attributes of the root element, as the declarations
+	// of the namespaces used by the elements not understood by the decoder.
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	// Warnings are the problems not stopping the reading: the orphan
+	// accounts and the errors of the objects skipped by a lenient read.
+	Warnings []error `xml:"-"`
 }
 
-// Slot type : integer | string | frame | gdate | numeric
-type Slot struct {
-	XMLName  xml.Name `xml:"slot"`
-	Key      string   `xml:"key"`
-	Value    string   `xml:"value"`
-	Children []*Slot
+// Flag type is true if the corresponding empty element is present,
+// as <cmdty:get_quotes/>.
+type Flag bool
+
+// UnmarshalXML implements xml.Unmarshaler interface
+func (f *Flag) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*f = true
+	return d.Skip()
 }
 
-// ReadFile read the gnucash file in XML format
+// ReadFile reads the gnucash file, detecting its format
+// by the file signature.
 func ReadFile(path string) (*Gnc, error) {
+	return LoadFile(path, "", nil)
+}
 
-	// open gnucash file
-	gnucashFile, err := os.Open(path)
+// gzipMagic are the first bytes of a gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ReadOptions type holds the options of ReadWithOptions.
+type ReadOptions struct {
+	// Lenient, if true, skips the objects of the book with errors,
+	// as a transaction with a split in an unknown account, keeping
+	// the errors in Gnc.Warnings. The syntax errors of the document
+	// are returned in any case.
+	Lenient bool
+}
+
+// Read reads a gnucash book in XML format from r.
+// The content is decompressed if it starts with the gzip magic bytes,
+// as GnuCash does when "compress files" is set.
+func Read(r io.Reader) (*Gnc, error) {
+	return ReadWithOptions(r, nil)
+}
+
+// ReadWithOptions reads a gnucash book in XML format from r, as Read.
+// A nil opts is the same as the zero ReadOptions.
+// The errors of the content are returned as *ParseError.
+func ReadWithOptions(r io.Reader, opts *ReadOptions) (*Gnc, error) {
+	reader, err := xmlReader(r)
 	if err != nil {
 		return nil, err
 	}
-	defer gnucashFile.Close()
+	if opts == nil {
+		opts = &ReadOptions{}
+	}
 
-	// decompress gnucash file
-	reader, err := gzip.NewReader(gnucashFile)
-	if err != nil {
-		return nil, err
+	var (
+		decoder = xml.NewDecoder(reader)
+		gnc     Gnc
+		root    *xml.StartElement
+		chart   = Chart{AccountMap: AccountMap{}}
+		br      = bookReader{lenient: opts.Lenient, chart: &chart}
+	)
+
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF && root != nil {
+			break
+		}
+		if err != nil {
+			if root == nil {
+				return nil, parseError(decoder, xml.Name{}, err)
+			}
+			return nil, parseError(decoder, root.Name, err)
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root == nil {
+			if se.Name.Local != "gnc-v2" && !isChartRoot(se.Name) {
+				err = fmt.Errorf("Not a GnuCash XML file: root element is <%s>", se.Name.Local)
+				return nil, parseError(decoder, xml.Name{}, err)
+			}
+			root = &se
+			gnc.XMLName = se.Name
+			gnc.Attrs = se.Attr
+			br.path = []xml.Name{se.Name}
+			continue
+		}
+		if se.Name.Local != "book" {
+			// element of an accounts-only file
+			if br.lenient {
+				err = br.isolated(decoder, se, br.chartObject)
+			} else if err = br.chartObject(decoder, se); err != nil {
+				err = parseError(decoder, se.Name, err)
+			}
+			if err != nil {
+				return nil, parseError(decoder, root.Name, err)
+			}
+			continue
+		}
+		var book Book
+		if err := book.unmarshalXML(decoder, se, &br); err != nil {
+			return nil, parseError(decoder, root.Name, err)
+		}
+		gnc.Book = &book
 	}
-	defer reader.Close()
+	if gnc.Book == nil {
+		br.warnings = append(br.warnings, chart.resolveAccounts()...)
+		gnc.Chart = &chart
+	}
+	gnc.Warnings = br.warnings
 
-	// unmarshall xml
-	gnc := Gnc{}
-	err = xml.NewDecoder(reader).Decode(&gnc)
+	return &gnc, nil
+}
+
+// xmlReader returns a reader of the XML content of r,
+// decompressed if r starts with the gzip magic bytes.
+func xmlReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		// decompress gnucash file
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr, nil
+	}
+	return br, nil
+}
+
+// utf8BOM is the byte order mark that may start a UTF-8 file.
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// xmlBackend type is the XML backend, compressed with gzip or not.
+type xmlBackend struct {
+	compressed bool
+}
+
+func init() {
+	Register(xmlBackend{compressed: true})
+	// before xml, that detects any XML file
+	Register(templateBackend{})
+	Register(xmlBackend{})
+}
+
+// Format returns "xml-gz" or "xml".
+func (b xmlBackend) Format() string {
+	if b.compressed {
+		return "xml-gz"
+	}
+	return "xml"
+}
+
+// Capabilities implements Backend interface.
+func (b xmlBackend) Capabilities() Capabilities {
+	return CanRead | CanWrite
+}
+
+// Detect implements Backend interface.
+func (b xmlBackend) Detect(header []byte) bool {
+	if b.compressed {
+		return bytes.HasPrefix(header, gzipMagic)
+	}
+	header = bytes.TrimLeft(bytes.TrimPrefix(header, utf8BOM), " \t\r\n")
+	return bytes.HasPrefix(header, []byte("<"))
+}
+
+// Load implements Backend interface.
+func (b xmlBackend) Load(path string, opts *ReadOptions) (*Gnc, error) {
+
+	// open gnucash file
+	gnucashFile, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
+	defer gnucashFile.Close()
 
-	return &gnc, nil
+	return ReadWithOptions(gnucashFile, opts)
+}
+
+// Save implements Saver interface.
+func (b xmlBackend) Save(path string, gnc *Gnc) error {
+	return WriteFile(path, gnc, &WriteOptions{Plain: !b.compressed})
 }
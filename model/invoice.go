@@ -0,0 +1,185 @@
+package model
+
+import (
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Invoice = element gnc:GncInvoice {
+  attribute version { "2.0.0" },
+  element invoice:guid { attribute type { "guid" }, GUID },
+  element invoice:id { text },
+  element invoice:owner { OwnerContent },
+  element invoice:opened { TimeSpec },
+  element invoice:posted { TimeSpec }?,
+  element invoice:terms { attribute type { "guid" }, GUID }?,
+  element invoice:billing_id { text }?,
+  element invoice:notes { text }?,
+  element invoice:active { xsd:boolean { pattern = "[01]" } },
+  element invoice:posttxn { attribute type { "guid" }, GUID }?,
+  element invoice:postlot { attribute type { "guid" }, GUID }?,
+  element invoice:postacc { attribute type { "guid" }, GUID }?,
+  element invoice:currency {
+    element cmdty:space { text },
+    element cmdty:id { text }
+  },
+  element invoice:billto { OwnerContent }?,
+  element invoice:charge-amt { GncNumeric }?,
+  element invoice:slots { KvpSlot+ }?
+}
+*/
+
+// Invoice type is a customer invoice, a vendor bill or an employee voucher,
+// depending on the type of the (end) owner.
+type Invoice struct {
+	ID           types.GUID     `xml:"guid"`
+	Number       string         `xml:"id"`
+	Owner        Owner          `xml:"owner"`
+	Opened       types.Timespec `xml:"opened"`
+	Posted       types.Timespec `xml:"posted"`
+	TermsID      types.GUID     `xml:"terms"`
+	BillingID    string         `xml:"billing_id"`
+	Notes        string         `xml:"notes"`
+	Active       bool           `xml:"active"`
+	PostTxnID    types.GUID     `xml:"posttxn"`
+	PostLotID    types.GUID     `xml:"postlot"`
+	PostAccID    types.GUID     `xml:"postacc"`
+	Currency     *Commodity     `xml:"currency"`
+	BillTo       *Owner         `xml:"billto"`
+	ChargeAmount types.Numeric  `xml:"charge-amt"`
+	Slots        Slots          `xml:"slots"`
+
+	// Resolved references.
+	Terms       *BillTerm    `xml:"-"`
+	PostTxn     *Transaction `xml:"-"`
+	PostLot     *Lot         `xml:"-"`
+	PostAccount *Account     `xml:"-"`
+	// Entries are the lines of the invoice.
+	Entries []*Entry `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// IsPosted returns true if the invoice has been posted to an account.
+func (inv *Invoice) IsPosted() bool {
+	return inv.PostTxn != nil
+}
+
+// IsCustomerDoc returns true for customer invoices, false for
+// vendor bills and employee vouchers.
+func (inv *Invoice) IsCustomerDoc() bool {
+	return inv.Owner.End().Type == OwnerTypeCustomer
+}
+
+// IsCreditNote returns true if the invoice is a credit note.
+func (inv *Invoice) IsCreditNote() bool {
+	v := inv.Slots.Get("credit-note")
+	return v != nil && v.Type == KvpTypeInteger && v.Integer != 0
+}
+
+// amounts returns the amounts of the entries of the invoice.
+// The entries of a credit note are stored negated, as GnuCash does:
+// the amounts are negated back, so that they are the positive amounts
+// of the document.
+func (inv *Invoice) amounts() (value, tax *types.Numeric) {
+	value, tax = &types.Numeric{}, &types.Numeric{}
+	cust := inv.IsCustomerDoc()
+	for _, e := range inv.Entries {
+		var ea EntryAmounts
+		if cust {
+			ea = e.InvoiceAmounts()
+		} else {
+			ea = e.BillAmounts()
+		}
+		value.AddEqual(ea.Value)
+		tax.AddEqual(ea.Tax)
+	}
+	if inv.IsCreditNote() {
+		value.NegEqual()
+		tax.NegEqual()
+	}
+	return
+}
+
+// Subtotal returns the total of the entries, taxes excluded.
+func (inv *Invoice) Subtotal() *types.Numeric {
+	value, _ := inv.amounts()
+	return value
+}
+
+// TaxTotal returns the total of the taxes of the entries.
+func (inv *Invoice) TaxTotal() *types.Numeric {
+	_, tax := inv.amounts()
+	return tax
+}
+
+// Total returns the total of the entries, taxes included.
+func (inv *Invoice) Total() *types.Numeric {
+	value, tax := inv.amounts()
+	return types.Add(value, tax)
+}
+
+// sign returns the sign of the posted amount in the post account:
+// invoices debit the A/R account, bills and vouchers credit the A/P account,
+// and credit notes do the opposite.
+func (inv *Invoice) sign() int {
+	sign := -1
+	if inv.IsCustomerDoc() {
+		sign = 1
+	}
+	if inv.IsCreditNote() {
+		sign = -sign
+	}
+	return sign
+}
+
+// PostedAmount returns the amount posted to the A/R or A/P account by the
+// posting transaction, with the same sign as Total.
+// It returns zero if the invoice is not posted.
+func (inv *Invoice) PostedAmount() *types.Numeric {
+	n := &types.Numeric{}
+	if inv.PostTxn == nil {
+		return n
+	}
+	for _, s := range inv.PostTxn.Splits {
+		if s.Account == inv.PostAccount && (inv.PostLot == nil || s.Lot == inv.PostLot) {
+			n.AddEqual(&s.Value)
+		}
+	}
+	if inv.sign() < 0 {
+		n.NegEqual()
+	}
+	return n
+}
+
+// Balance returns the amount still due: the balance of the post lot,
+// with the same sign as Total.
+// It returns zero if the invoice is not posted.
+func (inv *Invoice) Balance() *types.Numeric {
+	if inv.PostLot == nil {
+		return &types.Numeric{}
+	}
+	n := inv.PostLot.Balance()
+	if inv.sign() < 0 {
+		n.NegEqual()
+	}
+	return n
+}
+
+// DueDate returns the due date of the invoice: the one stored in the posting
+// transaction if any, else the one computed from the terms and the post date.
+func (inv *Invoice) DueDate() time.Time {
+	if inv.PostTxn != nil {
+		if v := inv.PostTxn.Slots.Get("trans-date-due"); v != nil && v.Type == KvpTypeTimespec {
+			return time.Time(v.Timespec)
+		}
+	}
+	posted := time.Time(inv.Posted)
+	if inv.Terms != nil {
+		return inv.Terms.DueDate(posted)
+	}
+	return posted
+}
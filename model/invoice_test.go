@@ -0,0 +1,397 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+const testInvoiceBook = `<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Receivable</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>RECEIVABLE</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+  <act:lots>
+    <gnc:lot version="2.0.0">
+      <lot:id type="guid">000000000000000000000000000000f1</lot:id>
+    </gnc:lot>
+  </act:lots>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Sales</act:name>
+  <act:id type="guid">000000000000000000000000000000c2</act:id>
+  <act:type>INCOME</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>VAT</act:name>
+  <act:id type="guid">000000000000000000000000000000c3</act:id>
+  <act:type>LIABILITY</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d1</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-03-10 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2016-03-10 10:59:00 +0100</ts:date></trn:date-entered>
+  <trn:description>Acme Srl</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e1</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>26960/100</split:value>
+      <split:quantity>26960/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+      <split:lot type="guid">000000000000000000000000000000f1</split:lot>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e2</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-23000/100</split:value>
+      <split:quantity>-23000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c2</split:account>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e3</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-3960/100</split:value>
+      <split:quantity>-3960/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c3</split:account>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d2</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-03-20 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2016-03-20 10:59:00 +0100</ts:date></trn:date-entered>
+  <trn:description>Payment</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e4</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-10000/100</split:value>
+      <split:quantity>-10000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+      <split:lot type="guid">000000000000000000000000000000f1</split:lot>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:GncBillTerm version="2.0.0">
+  <billterm:guid type="guid">000000000000000000000000000000b1</billterm:guid>
+  <billterm:name>30 days</billterm:name>
+  <billterm:desc>Net 30</billterm:desc>
+  <billterm:refcount>1</billterm:refcount>
+  <billterm:invisible>0</billterm:invisible>
+  <billterm:days>
+    <bt-days:due-days>30</bt-days:due-days>
+    <bt-days:disc-days>0</bt-days:disc-days>
+    <bt-days:discount>0/1</bt-days:discount>
+  </billterm:days>
+</gnc:GncBillTerm>
+<gnc:GncTaxTable version="2.0.0">
+  <taxtable:guid type="guid">000000000000000000000000000000b2</taxtable:guid>
+  <taxtable:name>VAT 22%</taxtable:name>
+  <taxtable:refcount>1</taxtable:refcount>
+  <taxtable:invisible>0</taxtable:invisible>
+  <taxtable:entries>
+    <gnc:GncTaxTableEntry>
+      <tte:acct type="guid">000000000000000000000000000000c3</tte:acct>
+      <tte:amount>22/1</tte:amount>
+      <tte:type>PERCENT</tte:type>
+    </gnc:GncTaxTableEntry>
+  </taxtable:entries>
+</gnc:GncTaxTable>
+<gnc:GncCustomer version="2.0.0">
+  <cust:guid type="guid">000000000000000000000000000000a1</cust:guid>
+  <cust:name>Acme Srl</cust:name>
+  <cust:id>000001</cust:id>
+  <cust:addr version="2.0.0"/>
+  <cust:shipaddr version="2.0.0"/>
+  <cust:terms type="guid">000000000000000000000000000000b1</cust:terms>
+  <cust:taxincluded>NO</cust:taxincluded>
+  <cust:active>1</cust:active>
+  <cust:discount>0/1</cust:discount>
+  <cust:credit>0/1</cust:credit>
+  <cust:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></cust:currency>
+  <cust:use-tt>1</cust:use-tt>
+  <cust:taxtable type="guid">000000000000000000000000000000b2</cust:taxtable>
+</gnc:GncCustomer>
+<gnc:GncInvoice version="2.0.0">
+  <invoice:guid type="guid">000000000000000000000000000000a2</invoice:guid>
+  <invoice:id>000001</invoice:id>
+  <invoice:owner version="2.0.0">
+    <owner:type>gncCustomer</owner:type>
+    <owner:id type="guid">000000000000000000000000000000a1</owner:id>
+  </invoice:owner>
+  <invoice:opened><ts:date>2016-03-09 00:00:00 +0100</ts:date></invoice:opened>
+  <invoice:posted><ts:date>2016-03-10 00:00:00 +0100</ts:date></invoice:posted>
+  <invoice:terms type="guid">000000000000000000000000000000b1</invoice:terms>
+  <invoice:billing_id>PO-42</invoice:billing_id>
+  <invoice:active>1</invoice:active>
+  <invoice:posttxn type="guid">000000000000000000000000000000d1</invoice:posttxn>
+  <invoice:postlot type="guid">000000000000000000000000000000f1</invoice:postlot>
+  <invoice:postacc type="guid">000000000000000000000000000000c1</invoice:postacc>
+  <invoice:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></invoice:currency>
+</gnc:GncInvoice>
+<gnc:GncEntry version="2.0.0">
+  <entry:guid type="guid">000000000000000000000000000000a3</entry:guid>
+  <entry:date><ts:date>2016-03-09 00:00:00 +0100</ts:date></entry:date>
+  <entry:entered><ts:date>2016-03-09 10:00:00 +0100</ts:date></entry:entered>
+  <entry:description>Consulting</entry:description>
+  <entry:action>Hours</entry:action>
+  <entry:qty>2/1</entry:qty>
+  <entry:i-acct type="guid">000000000000000000000000000000c2</entry:i-acct>
+  <entry:i-price>10000/100</entry:i-price>
+  <entry:i-discount>10/1</entry:i-discount>
+  <entry:invoice type="guid">000000000000000000000000000000a2</entry:invoice>
+  <entry:i-disc-type>PERCENT</entry:i-disc-type>
+  <entry:i-disc-how>PRETAX</entry:i-disc-how>
+  <entry:i-taxable>1</entry:i-taxable>
+  <entry:i-taxincluded>0</entry:i-taxincluded>
+  <entry:i-taxtable type="guid">000000000000000000000000000000b2</entry:i-taxtable>
+</gnc:GncEntry>
+<gnc:GncEntry version="2.0.0">
+  <entry:guid type="guid">000000000000000000000000000000a4</entry:guid>
+  <entry:date><ts:date>2016-03-09 00:00:00 +0100</ts:date></entry:date>
+  <entry:entered><ts:date>2016-03-09 10:00:00 +0100</ts:date></entry:entered>
+  <entry:description>Stamp duty</entry:description>
+  <entry:qty>1/1</entry:qty>
+  <entry:i-acct type="guid">000000000000000000000000000000c2</entry:i-acct>
+  <entry:i-price>5000/100</entry:i-price>
+  <entry:i-discount>0/1</entry:i-discount>
+  <entry:invoice type="guid">000000000000000000000000000000a2</entry:invoice>
+  <entry:i-disc-how>PRETAX</entry:i-disc-how>
+  <entry:i-taxable>0</entry:i-taxable>
+  <entry:i-taxincluded>0</entry:i-taxincluded>
+</gnc:GncEntry>
+`
+
+// equalNum compares the values of two numerics.
+func equalNum(x, y *types.Numeric) bool {
+	return types.Sub(x, y).IsZero()
+}
+
+func TestInvoice(t *testing.T) {
+	book := readTestBook(t, testInvoiceBook)
+	biz := &book.Business
+
+	cust := biz.Customer("000000000000000000000000000000a1")
+	if cust.Terms == nil || cust.Terms.Name != "30 days" {
+		t.Errorf("Customer.Terms: not resolved")
+	}
+	if cust.TaxTable == nil || cust.TaxTable.Name != "VAT 22%" {
+		t.Errorf("Customer.TaxTable: not resolved")
+	}
+	if tte := cust.TaxTable.Entries[0]; tte.Account != book.AccountMap["000000000000000000000000000000c3"] {
+		t.Errorf("TaxTableEntry.Account: not resolved")
+	}
+
+	inv := biz.Invoice("000000000000000000000000000000a2")
+	if inv == nil {
+		t.Fatalf("Invoice: not found")
+	}
+	if !inv.IsPosted() || !inv.IsCustomerDoc() || inv.Owner.Customer != cust {
+		t.Errorf("Invoice: unexpected values %+v", inv)
+	}
+	if inv.PostTxn != book.TransactionMap["000000000000000000000000000000d1"] {
+		t.Errorf("Invoice.PostTxn: not resolved")
+	}
+	if inv.PostLot != book.LotMap["000000000000000000000000000000f1"] {
+		t.Errorf("Invoice.PostLot: not resolved")
+	}
+	if inv.PostAccount == nil || inv.PostAccount.Name != "Receivable" {
+		t.Errorf("Invoice.PostAccount: not resolved")
+	}
+	if len(inv.Entries) != 2 || inv.Entries[0].Invoice != inv {
+		t.Fatalf("Invoice.Entries: expected 2 linked entries, got %d", len(inv.Entries))
+	}
+
+	var testCases = []struct {
+		name     string
+		actual   *types.Numeric
+		expected string
+	}{
+		{"Subtotal", inv.Subtotal(), "230"},
+		{"TaxTotal", inv.TaxTotal(), "396/10"},
+		{"Total", inv.Total(), "2696/10"},
+		{"PostedAmount", inv.PostedAmount(), "2696/10"},
+		{"Balance", inv.Balance(), "1696/10"},
+	}
+	for _, tc := range testCases {
+		expected, _ := types.FromString(tc.expected)
+		if !equalNum(tc.actual, expected) {
+			t.Errorf("Invoice.%s: expected %s, got %s", tc.name, tc.expected, tc.actual)
+		}
+	}
+
+	due := time.Date(2016, 4, 9, 0, 0, 0, 0, time.UTC)
+	if y, m, d := inv.DueDate().Date(); y != due.Year() || m != due.Month() || d != due.Day() {
+		t.Errorf("Invoice.DueDate: expected %s, got %s", due.Format("2006-01-02"), inv.DueDate())
+	}
+
+	if invs := biz.OwnerInvoices(cust.ID); len(invs) != 1 || invs[0] != inv {
+		t.Errorf("OwnerInvoices: unexpected result %v", invs)
+	}
+}
+
+// testCreditNoteBook is testInvoiceBook with the invoice turned into a
+// credit note: the entries are stored negated, the A/R account is credited
+// and the payment is a refund.
+func testCreditNoteBook() string {
+	return strings.NewReplacer(
+		"<invoice:active>1</invoice:active>", `<invoice:active>1</invoice:active>
+  <invoice:slots>
+    <slot>
+      <slot:key>credit-note</slot:key>
+      <slot:value type="integer">1</slot:value>
+    </slot>
+  </invoice:slots>`,
+		"<entry:qty>2/1<", "<entry:qty>-2/1<",
+		"<entry:qty>1/1<", "<entry:qty>-1/1<",
+		">26960/100<", ">-26960/100<",
+		">-23000/100<", ">23000/100<",
+		">-3960/100<", ">3960/100<",
+		">-10000/100<", ">10000/100<",
+	).Replace(testInvoiceBook)
+}
+
+func TestCreditNote(t *testing.T) {
+	book := readTestBook(t, testCreditNoteBook())
+	inv := book.Business.Invoice("000000000000000000000000000000a2")
+	if inv == nil || !inv.IsCreditNote() {
+		t.Fatalf("Invoice: expected a credit note")
+	}
+
+	var testCases = []struct {
+		name     string
+		actual   *types.Numeric
+		expected string
+	}{
+		{"Subtotal", inv.Subtotal(), "230"},
+		{"TaxTotal", inv.TaxTotal(), "396/10"},
+		{"Total", inv.Total(), "2696/10"},
+		{"PostedAmount", inv.PostedAmount(), "2696/10"},
+		{"Balance", inv.Balance(), "1696/10"},
+	}
+	for _, tc := range testCases {
+		expected, _ := types.FromString(tc.expected)
+		if !equalNum(tc.actual, expected) {
+			t.Errorf("CreditNote.%s: expected %s, got %s", tc.name, tc.expected, tc.actual)
+		}
+	}
+}
+
+func TestEntryAmounts(t *testing.T) {
+	vat := &TaxTable{Entries: []*TaxTableEntry{
+		{Amount: *types.New(22, 1), Type: AmountTypePercent},
+	}}
+	stamp := &TaxTable{Entries: []*TaxTableEntry{
+		{Amount: *types.New(22, 1), Type: AmountTypePercent},
+		{Amount: *types.New(200, 100), Type: AmountTypeValue},
+	}}
+
+	var testCases = []struct {
+		name        string
+		entry       Entry
+		value, tax  string
+		discountAmt string
+	}{
+		{"no discount", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(100, 1), InvTaxable: true, InvTaxTable: vat},
+			"100", "22", "0"},
+		{"pretax", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(100, 1), InvTaxable: true, InvTaxTable: vat,
+			InvDiscount: *types.New(10, 1), InvDiscountType: AmountTypePercent, InvDiscountHow: DiscountPreTax},
+			"90", "198/10", "10"},
+		{"sametime", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(100, 1), InvTaxable: true, InvTaxTable: vat,
+			InvDiscount: *types.New(10, 1), InvDiscountType: AmountTypePercent, InvDiscountHow: DiscountSameTime},
+			"90", "22", "10"},
+		{"posttax", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(100, 1), InvTaxable: true, InvTaxTable: vat,
+			InvDiscount: *types.New(10, 1), InvDiscountType: AmountTypePercent, InvDiscountHow: DiscountPostTax},
+			"878/10", "22", "122/10"},
+		{"value discount", Entry{Quantity: *types.New(2, 1), InvPrice: *types.New(100, 1), InvTaxable: true, InvTaxTable: vat,
+			InvDiscount: *types.New(15, 1), InvDiscountType: AmountTypeValue, InvDiscountHow: DiscountPreTax},
+			"185", "407/10", "15"},
+		{"tax included", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(122, 1), InvTaxable: true, InvTaxTable: vat,
+			InvTaxIncluded: true},
+			"100", "22", "0"},
+		{"value tax", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(100, 1), InvTaxable: true, InvTaxTable: stamp},
+			"100", "24", "0"},
+		{"not taxable", Entry{Quantity: *types.New(1, 1), InvPrice: *types.New(100, 1), InvTaxable: false, InvTaxTable: vat},
+			"100", "0", "0"},
+		// rounded half up to the 100000 SCU of the entries without invoice
+		{"rounded", Entry{Quantity: *types.New(1, 3), InvPrice: *types.New(1, 1), InvTaxable: true, InvTaxTable: vat},
+			"33333/100000", "7333/100000", "0"},
+		// rounded half up to the 100 SCU of the invoice currency
+		{"invoice currency", Entry{Quantity: *types.New(1, 3), InvPrice: *types.New(1, 1), InvTaxable: true, InvTaxTable: vat,
+			Invoice: &Invoice{Currency: &Commodity{Space: "ISO4217", ID: "EUR"}}},
+			"33/100", "7/100", "0"},
+	}
+
+	for _, tc := range testCases {
+		ea := tc.entry.InvoiceAmounts()
+		for _, x := range []struct {
+			what     string
+			actual   *types.Numeric
+			expected string
+		}{
+			{"value", ea.Value, tc.value},
+			{"tax", ea.Tax, tc.tax},
+			{"discount", ea.Discount, tc.discountAmt},
+		} {
+			expected, _ := types.FromString(x.expected)
+			if !equalNum(x.actual, expected) {
+				t.Errorf("%s: %s expected %s, got %s", tc.name, x.what, x.expected, x.actual)
+			}
+		}
+	}
+
+	// bill lines ignore the invoice discount
+	e := Entry{Quantity: *types.New(3, 1), BillPrice: *types.New(10, 1), BillTaxable: true, BillTaxTable: vat,
+		InvDiscount: *types.New(10, 1), InvDiscountType: AmountTypePercent}
+	ea := e.BillAmounts()
+	if expected, _ := types.FromString("366/10"); !equalNum(ea.Total(), expected) {
+		t.Errorf("BillAmounts: expected total 36.6, got %s", ea.Total())
+	}
+}
+
+func TestBillTermDueDate(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+	days := &BillTerm{Days: &BillTermDays{DueDays: 30}}
+	prox := &BillTerm{Proximo: &BillTermProximo{DueDay: 10, CutoffDay: 25}}
+	endOfMonth := &BillTerm{Proximo: &BillTermProximo{DueDay: 31}}
+
+	var testCases = []struct {
+		bt       *BillTerm
+		posted   string
+		expected string
+	}{
+		{days, "2016-03-10", "2016-04-09"},
+		{prox, "2016-03-20", "2016-04-10"},
+		{prox, "2016-03-28", "2016-05-10"},
+		{prox, "2016-12-28", "2017-02-10"},
+		{endOfMonth, "2016-01-10", "2016-02-29"},
+		{&BillTerm{}, "2016-01-10", "2016-01-10"},
+	}
+	for _, tc := range testCases {
+		actual := tc.bt.DueDate(date(tc.posted))
+		if !actual.Equal(date(tc.expected)) {
+			t.Errorf("DueDate(%s): expected %s, got %s", tc.posted, tc.expected, actual.Format("2006-01-02"))
+		}
+	}
+}
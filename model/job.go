@@ -0,0 +1,30 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Job = element gnc:GncJob {
+  attribute version { "2.0.0" },
+  element job:guid { attribute type { "guid" }, GUID },
+  element job:id { text },
+  element job:name { text },
+  element job:reference { text }?,
+  element job:owner { OwnerContent },
+  element job:active { xsd:boolean { pattern = "[01]" } }
+}
+*/
+
+// Job type
+type Job struct {
+	ID        types.GUID `xml:"guid"`
+	Number    string     `xml:"id"`
+	Name      string     `xml:"name"`
+	Reference string     `xml:"reference"`
+	Owner     Owner      `xml:"owner"`
+	Active    bool       `xml:"active"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
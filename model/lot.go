@@ -0,0 +1,154 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Lot = element gnc:lot {
+  attribute version { "2.0.0" },
+  element lot:id { attribute type { "guid" }, GUID },
+  element lot:slots { KvpSlot+ }
+}
+*/
+
+// Lots type is a list of Lot.
+type Lots []*Lot
+
+// Lot type
+type Lot struct {
+	ID      types.GUID
+	Slots   Slots
+	Account *Account
+	// Splits of the lot, sorted by transaction DatePosted.
+	Splits Splits
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// LotMap maps the GUID of a lot to the lot.
+type LotMap map[types.GUID]*Lot
+
+func lotsUnmarshalXML(decoder *xml.Decoder) (Lots, error) {
+	var lots Lots
+
+LOOP:
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			if se.Name.Local == "lot" {
+				lot, err := lotUnmarshalXML(decoder)
+				if err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				lot.addAttrs(se.Attr)
+				lots = append(lots, lot)
+			}
+		case xml.EndElement:
+			if se.Name.Local == "lots" {
+				break LOOP
+			}
+		}
+	}
+
+	return lots, nil
+}
+
+func lotUnmarshalXML(decoder *xml.Decoder) (*Lot, error) {
+	var lot Lot
+
+LOOP:
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			var v interface{}
+
+			switch se.Name.Local {
+			case "id":
+				v = &lot.ID
+			case "slots":
+				v = &lot.Slots
+			default:
+				if err := lot.addElement(decoder, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+			}
+
+			if v != nil {
+				if err := decoder.DecodeElement(v, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+			}
+		case xml.EndElement:
+			if se.Name.Local == "lot" {
+				break LOOP
+			}
+		}
+	}
+	if lot.ID == "" {
+		return nil, fmt.Errorf("Lot without ID")
+	}
+
+	return &lot, nil
+}
+
+// Title returns the title of the lot.
+func (lot *Lot) Title() string {
+	return lot.Slots.GetString("title")
+}
+
+// Notes returns the notes of the lot.
+func (lot *Lot) Notes() string {
+	return lot.Slots.GetString("notes")
+}
+
+// Balance returns the sum of the quantities of the splits of the lot,
+// i.e. the amount of commodity still held by the lot.
+func (lot *Lot) Balance() *types.Numeric {
+	var balance types.Numeric
+	for _, s := range lot.Splits {
+		balance.AddEqual(&s.Quantity)
+	}
+	return &balance
+}
+
+// Value returns the sum of the values of the splits of the lot.
+func (lot *Lot) Value() *types.Numeric {
+	var value types.Numeric
+	for _, s := range lot.Splits {
+		value.AddEqual(&s.Value)
+	}
+	return &value
+}
+
+// IsClosed returns true if the lot has splits and a zero balance.
+// As in GnuCash, a lot without splits is open.
+func (lot *Lot) IsClosed() bool {
+	return len(lot.Splits) > 0 && lot.Balance().IsZero()
+}
+
+// Add adds a lot to the collection
+func (lots *Lots) Add(lot *Lot) {
+	*lots = append(*lots, lot)
+}
+
+// Len returns the number of lots.
+func (lots Lots) Len() int {
+	return len(lots)
+}
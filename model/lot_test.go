@@ -0,0 +1,169 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+const testLotsBook = `<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>ACME</act:name>
+  <act:id type="guid">000000000000000000000000000000c3</act:id>
+  <act:type>STOCK</act:type>
+  <act:commodity><cmdty:space>NASDAQ</cmdty:space><cmdty:id>ACME</cmdty:id></act:commodity>
+  <act:commodity-scu>10000</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+  <act:lots>
+    <gnc:lot version="2.0.0">
+      <lot:id type="guid">000000000000000000000000000000f1</lot:id>
+      <lot:slots>
+        <slot>
+          <slot:key>title</slot:key>
+          <slot:value type="string">Lot 1</slot:value>
+        </slot>
+      </lot:slots>
+    </gnc:lot>
+    <gnc:lot version="2.0.0">
+      <lot:id type="guid">000000000000000000000000000000f2</lot:id>
+      <lot:slots>
+        <slot>
+          <slot:key>title</slot:key>
+          <slot:value type="string">Lot 2</slot:value>
+        </slot>
+      </lot:slots>
+    </gnc:lot>
+  </act:lots>
+</gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d1</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2014-01-10 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2014-01-10 10:59:00 +0100</ts:date></trn:date-entered>
+  <trn:description>Buy ACME</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e1</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>100000/100</split:value>
+      <split:quantity>100000/10000</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c3</split:account>
+      <split:lot type="guid">000000000000000000000000000000f1</split:lot>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e2</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-100000/100</split:value>
+      <split:quantity>-100000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d3</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-06-15 00:00:00 +0200</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2016-06-15 10:59:00 +0200</ts:date></trn:date-entered>
+  <trn:description>Sell ACME</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e5</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-150000/100</split:value>
+      <split:quantity>-100000/10000</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c3</split:account>
+      <split:lot type="guid">000000000000000000000000000000f1</split:lot>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e6</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>150000/100</split:value>
+      <split:quantity>150000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d2</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2015-03-02 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2015-03-02 10:59:00 +0100</ts:date></trn:date-entered>
+  <trn:description>Buy ACME</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e3</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>60000/100</split:value>
+      <split:quantity>50000/10000</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c3</split:account>
+      <split:lot type="guid">000000000000000000000000000000f2</split:lot>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e4</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>-60000/100</split:value>
+      <split:quantity>-60000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+`
+
+func TestLots(t *testing.T) {
+	book := readTestBook(t, testLotsBook)
+
+	acme := book.AccountMap["000000000000000000000000000000c3"]
+	if acme.Lots.Len() != 2 {
+		t.Fatalf("Account.Lots: expected 2 lots, got %d", acme.Lots.Len())
+	}
+
+	var testCases = []struct {
+		id      string
+		title   string
+		splits  int
+		balance string
+		closed  bool
+	}{
+		{"000000000000000000000000000000f1", "Lot 1", 2, "0", true},
+		{"000000000000000000000000000000f2", "Lot 2", 1, "50000/10000", false},
+	}
+	for _, tc := range testCases {
+		lot := book.LotMap[types.GUID(tc.id)]
+		if lot == nil {
+			t.Errorf("LotMap[%s]: lot not found", tc.id)
+			continue
+		}
+		if lot.Account != acme {
+			t.Errorf("Lot(%s).Account: expected %v, got %v", tc.title, acme, lot.Account)
+		}
+		if lot.Title() != tc.title {
+			t.Errorf("Lot(%s).Title: got %q", tc.title, lot.Title())
+		}
+		if lot.Splits.Len() != tc.splits {
+			t.Errorf("Lot(%s).Splits: expected %d, got %d", tc.title, tc.splits, lot.Splits.Len())
+		}
+		if s := lot.Balance().String(); s != tc.balance {
+			t.Errorf("Lot(%s).Balance: expected %s, got %s", tc.title, tc.balance, s)
+		}
+		if lot.IsClosed() != tc.closed {
+			t.Errorf("Lot(%s).IsClosed: expected %v", tc.title, tc.closed)
+		}
+		for _, s := range lot.Splits {
+			if s.Lot != lot {
+				t.Errorf("Lot(%s): split %s not linked to the lot", tc.title, s.ID)
+			}
+		}
+	}
+}
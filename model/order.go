@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Order = element gnc:GncOrder {
+  attribute version { "2.0.0" },
+  element order:guid { attribute type { "guid" }, GUID },
+  element order:id { text },
+  element order:owner { OwnerContent },
+  element order:opened { TimeSpec },
+  element order:closed { TimeSpec }?,
+  element order:notes { text }?,
+  element order:reference { text }?,
+  element order:active { xsd:boolean { pattern = "[01]" } }
+}
+*/
+
+// Order type
+type Order struct {
+	ID        types.GUID     `xml:"guid"`
+	Number    string         `xml:"id"`
+	Owner     Owner          `xml:"owner"`
+	Opened    types.Timespec `xml:"opened"`
+	Closed    types.Timespec `xml:"closed"`
+	Notes     string         `xml:"notes"`
+	Reference string         `xml:"reference"`
+	Active    bool           `xml:"active"`
+
+	// Entries of the order.
+	Entries []*Entry `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"encoding/xml"
+	"sort"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+PriceDb = element gnc:pricedb {
+  attribute version { "1" },
+  Price+
+}
+
+Price = element price {
+  element price:id { attribute type { "guid" }, GUID },
+  element price:commodity {
+    element cmdty:space { text },
+    element cmdty:id { text }
+  },
+  element price:currency {
+    element cmdty:space { text },
+    element cmdty:id { text }
+  },
+  element price:time { TimeSpec },
+  element price:source { text }?,
+  element price:type { "bid" | "ask" | "last" | "nav" | "unknown" }?,
+  element price:value { GncNumeric }
+}
+*/
+
+// PriceDB represents the GnuCash price database.
+// Prices are kept sorted by Time.
+type PriceDB struct {
+	Prices []*Price
+}
+
+// Price type
+type Price struct {
+	ID        types.GUID
+	Commodity *Commodity
+	Currency  *Commodity
+	Time      types.Timespec
+	Source    string
+	Type      string
+	Value     types.Numeric
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// Convert returns the value in the price currency of the amount of the
+// price commodity, rounded half up to the SCU of the currency, as
+// gnc_pricedb_convert_balance_latest_price does.
+func (p *Price) Convert(amount *types.Numeric) *types.Numeric {
+	return types.Mul(amount, &p.Value).Convert(p.Currency.SCU(), types.RoundHalfUp)
+}
+
+func priceUnmarshalXML(decoder *xml.Decoder, commodities Commodities) (*Price, error) {
+
+	var price Price
+
+LOOP:
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			var v interface{}
+			var cmdty Commodity
+
+			switch se.Name.Local {
+			case "id":
+				v = &price.ID
+			case "commodity", "currency":
+				v = &cmdty
+			case "time":
+				v = &price.Time
+			case "source":
+				v = &price.Source
+			case "type":
+				v = &price.Type
+			case "value":
+				v = &price.Value
+			default:
+				if err := price.addElement(decoder, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+			}
+
+			if v != nil {
+				if err := decoder.DecodeElement(v, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				switch se.Name.Local {
+				case "commodity":
+					price.Commodity = commodities.Lookup(&cmdty)
+				case "currency":
+					price.Currency = commodities.Lookup(&cmdty)
+				}
+			}
+
+		case xml.EndElement:
+			if se.Name.Local == "price" {
+				break LOOP
+			}
+		}
+	}
+
+	return &price, nil
+}
+
+func priceDBUnmarshalXML(decoder *xml.Decoder, commodities Commodities) (*PriceDB, error) {
+
+	var db PriceDB
+
+LOOP:
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			if se.Name.Local == "price" {
+				p, err := priceUnmarshalXML(decoder, commodities)
+				if err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				p.addAttrs(se.Attr)
+				db.Add(p)
+			}
+		case xml.EndElement:
+			if se.Name.Local == "pricedb" {
+				break LOOP
+			}
+		}
+	}
+	db.Sort()
+
+	return &db, nil
+}
+
+// Add adds a price to the database.
+// Call Sort after adding prices out of time order.
+func (db *PriceDB) Add(p *Price) {
+	db.Prices = append(db.Prices, p)
+}
+
+// Len returns the number of prices.
+func (db *PriceDB) Len() int {
+	return len(db.Prices)
+}
+
+// Sort sorts the prices by Time.
+func (db *PriceDB) Sort() {
+	sort.SliceStable(db.Prices, func(i, j int) bool {
+		return time.Time(db.Prices[i].Time).Before(time.Time(db.Prices[j].Time))
+	})
+}
+
+// List returns the prices of cmdty expressed in currency, sorted by Time.
+func (db *PriceDB) List(cmdty, currency *Commodity) []*Price {
+	var list []*Price
+	for _, p := range db.Prices {
+		if p.Commodity.Is(cmdty) && p.Currency.Is(currency) {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// Latest returns the latest price of cmdty expressed in currency
+// on or before date. It returns nil if no such price exists.
+func (db *PriceDB) Latest(cmdty, currency *Commodity, date time.Time) *Price {
+	for j := len(db.Prices) - 1; j >= 0; j-- {
+		p := db.Prices[j]
+		if time.Time(p.Time).After(date) {
+			continue
+		}
+		if p.Commodity.Is(cmdty) && p.Currency.Is(currency) {
+			return p
+		}
+	}
+	return nil
+}
+
+// Nearest returns the price of cmdty expressed in currency
+// whose Time is closest to date, before or after it.
+// It returns nil if no such price exists.
+func (db *PriceDB) Nearest(cmdty, currency *Commodity, date time.Time) *Price {
+	var (
+		best     *Price
+		bestDist time.Duration
+	)
+	for _, p := range db.Prices {
+		if !(p.Commodity.Is(cmdty) && p.Currency.Is(currency)) {
+			continue
+		}
+		dist := time.Time(p.Time).Sub(date)
+		if dist < 0 {
+			dist = -dist
+		}
+		if best == nil || dist < bestDist {
+			best, bestDist = p, dist
+		}
+	}
+	return best
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+const testPriceDB = `<gnc:pricedb version="1">
+  <price>
+    <price:id type="guid">0000000000000000000000000000a001</price:id>
+    <price:commodity><cmdty:space>NASDAQ</cmdty:space><cmdty:id>ACME</cmdty:id></price:commodity>
+    <price:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></price:currency>
+    <price:time><ts:date>2016-03-01 10:59:00 +0100</ts:date></price:time>
+    <price:source>user:price-editor</price:source>
+    <price:type>last</price:type>
+    <price:value>1250/100</price:value>
+  </price>
+  <price>
+    <price:id type="guid">0000000000000000000000000000a002</price:id>
+    <price:commodity><cmdty:space>NASDAQ</cmdty:space><cmdty:id>ACME</cmdty:id></price:commodity>
+    <price:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></price:currency>
+    <price:time><ts:date>2016-01-01 10:59:00 +0100</ts:date></price:time>
+    <price:source>Finance::Quote</price:source>
+    <price:value>1000/100</price:value>
+  </price>
+</gnc:pricedb>
+`
+
+func TestPriceConvert(t *testing.T) {
+	book := readTestBook(t, testPriceDB)
+	p := book.PriceDB.Prices[0]
+
+	// 3.3333 ACME at 10.00 EUR = 33.333 EUR
+	if actual := p.Convert(types.New(33333, 10000)); actual.GncString() != "3333/100" {
+		t.Errorf("Convert: expected 3333/100, got %s", actual.GncString())
+	}
+}
+
+func TestPriceDB(t *testing.T) {
+	book := readTestBook(t, testPriceDB)
+
+	if book.Commodities.Len() != 2 {
+		t.Fatalf("Commodities: expected 2, got %d", book.Commodities.Len())
+	}
+	if book.PriceDB.Len() != 2 {
+		t.Fatalf("PriceDB: expected 2 prices, got %d", book.PriceDB.Len())
+	}
+
+	acme := book.Commodities.Get("NASDAQ", "ACME")
+	eur := book.Commodities.Get("ISO4217", "EUR")
+	if p := book.PriceDB.Prices[0]; p.Commodity != acme || p.Currency != eur {
+		t.Errorf("Price: commodities not resolved: %v %v", p.Commodity, p.Currency)
+	}
+
+	date := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	var testCases = []struct {
+		date     string
+		expected string
+	}{
+		{"2015-12-31", ""},
+		{"2016-01-02", "1000/100"},
+		{"2016-02-29", "1000/100"},
+		{"2016-03-02", "1250/100"},
+	}
+	for _, tc := range testCases {
+		p := book.PriceDB.Latest(acme, eur, date(tc.date))
+		actual := ""
+		if p != nil {
+			actual = p.Value.String()
+		}
+		if actual != tc.expected {
+			t.Errorf("Latest(%s): expected %q, got %q", tc.date, tc.expected, actual)
+		}
+	}
+
+	if p := book.PriceDB.Latest(eur, acme, date("2017-01-01")); p != nil {
+		t.Errorf("Latest: expected nil for inverted commodities, got %v", p.Value)
+	}
+	if p := book.PriceDB.Nearest(acme, eur, date("2015-01-01")); p == nil || p.Source != "Finance::Quote" {
+		t.Errorf("Nearest: expected the oldest price, got %v", p)
+	}
+}
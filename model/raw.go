@@ -0,0 +1,199 @@
+package model
+
+import (
+	"encoding/xml"
+	"sort"
+)
+
+// RawElement type is an XML element not understood by the decoders,
+// kept to be written back verbatim.
+type RawElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// Unknown type holds the attributes and the child elements of an object
+// not understood by the decoders. It is embedded by the objects of the book,
+// so that they can be written back without losing data.
+type Unknown struct {
+	UnknownAttrs    []xml.Attr    `xml:",any,attr"`
+	UnknownElements []*RawElement `xml:",any"`
+}
+
+// isKnownAttr returns true for the attributes handled by the writer.
+func isKnownAttr(a xml.Attr) bool {
+	return a.Name.Space == "" && a.Name.Local == "version"
+}
+
+// attrs returns the unknown attributes, skipping the ones handled by the writer.
+func (u *Unknown) attrs() []xml.Attr {
+	var list []xml.Attr
+	for _, a := range u.UnknownAttrs {
+		if !isKnownAttr(a) {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// addAttrs keeps the attributes of the start element of the object,
+// skipping the ones handled by the writer.
+func (u *Unknown) addAttrs(attrs []xml.Attr) {
+	for _, a := range attrs {
+		if !isKnownAttr(a) {
+			u.UnknownAttrs = append(u.UnknownAttrs, a)
+		}
+	}
+}
+
+// addElement reads the element started by se and keeps it as raw XML.
+func (u *Unknown) addElement(decoder *xml.Decoder, se *xml.StartElement) error {
+	var raw RawElement
+	if err := decoder.DecodeElement(&raw, se); err != nil {
+		return err
+	}
+	u.UnknownElements = append(u.UnknownElements, &raw)
+	return nil
+}
+
+// prefixes maps the known namespace URIs to their prefix.
+var prefixes = func() map[string]string {
+	m := map[string]string{}
+	for _, ns := range namespaces {
+		m[ns.uri] = ns.prefix
+	}
+	return m
+}()
+
+// qualifiedName returns the name with the prefix of its namespace.
+// The decoder leaves the prefix as namespace when it is not declared;
+// names of namespaces without a known prefix are returned as "{uri}local".
+func qualifiedName(name xml.Name, local map[string]string) string {
+	switch {
+	case name.Space == "":
+		return name.Local
+	case name.Space == "xmlns":
+		return "xmlns:" + name.Local
+	}
+	if p, ok := prefixes[name.Space]; ok {
+		return p + ":" + name.Local
+	}
+	if p, ok := local[name.Space]; ok {
+		return p + ":" + name.Local
+	}
+	if len(name.Space) > 0 && !isURI(name.Space) {
+		return name.Space + ":" + name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
+
+// isURI returns true if s looks like a namespace URI rather than a prefix.
+func isURI(s string) bool {
+	for _, c := range s {
+		if c == ':' || c == '/' {
+			return true
+		}
+	}
+	return false
+}
+
+// nsDecls returns the prefixes of the namespaces declared by the attributes.
+func nsDecls(attrs []xml.Attr, m map[string]string) map[string]string {
+	for _, a := range attrs {
+		if a.Name.Space == "xmlns" {
+			if m == nil {
+				m = map[string]string{}
+			}
+			m[a.Value] = a.Name.Local
+		}
+	}
+	return m
+}
+
+// Preserved returns the elements and attributes of the book preserved but
+// not understood by the decoders, with their number of occurrences.
+// The keys are "owner/name", as "gnc:account/act:color" for an element
+// and "gnc:account/@foo" for an attribute; the names of the namespaces
+// not known to GnuCash are given as "{uri}local".
+func (b *Book) Preserved() map[string]int {
+	m := map[string]int{}
+	add := func(owner string, u *Unknown) {
+		for _, a := range u.attrs() {
+			m[owner+"/@"+qualifiedName(a.Name, nil)]++
+		}
+		for _, e := range u.UnknownElements {
+			m[owner+"/"+qualifiedName(e.XMLName, nsDecls(e.Attrs, nil))]++
+		}
+	}
+
+	add("gnc:book", &b.Unknown)
+	for _, c := range b.Commodities {
+		add("gnc:commodity", &c.Unknown)
+	}
+	for _, p := range b.PriceDB.Prices {
+		add("price", &p.Unknown)
+	}
+	for _, list := range []*Accounts{&b.Accounts, &b.TemplateAccounts} {
+		for _, acc := range list.List {
+			add("gnc:account", &acc.Unknown)
+			for _, lot := range acc.Lots {
+				add("gnc:lot", &lot.Unknown)
+			}
+		}
+	}
+	for _, list := range []Transactions{b.Transactions, b.TemplateTransactions} {
+		for _, t := range list {
+			add("gnc:transaction", &t.Unknown)
+			for _, s := range t.Splits {
+				add("trn:split", &s.Unknown)
+			}
+		}
+	}
+	for _, sx := range b.ScheduledTransactions {
+		add("gnc:schedxaction", &sx.Unknown)
+	}
+	for _, bgt := range b.Budgets {
+		add("gnc:budget", &bgt.Unknown)
+	}
+
+	biz := &b.Business
+	for _, x := range biz.BillTerms {
+		add("gnc:GncBillTerm", &x.Unknown)
+	}
+	for _, x := range biz.Customers {
+		add("gnc:GncCustomer", &x.Unknown)
+	}
+	for _, x := range biz.Employees {
+		add("gnc:GncEmployee", &x.Unknown)
+	}
+	for _, x := range biz.Entries {
+		add("gnc:GncEntry", &x.Unknown)
+	}
+	for _, x := range biz.Invoices {
+		add("gnc:GncInvoice", &x.Unknown)
+	}
+	for _, x := range biz.Jobs {
+		add("gnc:GncJob", &x.Unknown)
+	}
+	for _, x := range biz.Orders {
+		add("gnc:GncOrder", &x.Unknown)
+	}
+	for _, x := range biz.TaxTables {
+		add("gnc:GncTaxTable", &x.Unknown)
+	}
+	for _, x := range biz.Vendors {
+		add("gnc:GncVendor", &x.Unknown)
+	}
+	return m
+}
+
+// PreservedKeys returns the sorted keys of Preserved.
+func PreservedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,369 @@
+package model
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+ScheduledTransaction = element gnc:schedxaction {
+  attribute version { "2.0.0" },
+  element sx:id { attribute type { "guid" }, GUID },
+  element sx:name { text },
+  element sx:enabled { "y" | "n" },
+  element sx:autoCreate { "y" | "n" },
+  element sx:autoCreateNotify { "y" | "n" },
+  element sx:advanceCreateDays { xsd:int },
+  element sx:advanceRemindDays { xsd:int },
+  element sx:instanceCount { xsd:int },
+  element sx:start { GDate },
+  element sx:last { GDate }?,
+  ( ( element sx:num-occur { xsd:int },
+      element sx:rem-occur { xsd:int }
+    )
+  | element sx:end { GDate }
+  )?,
+  element sx:templ-acct { attribute type { "guid" }, GUID },
+  element sx:schedule {
+    element gnc:recurrence { RecurrenceContent }*
+  },
+  DeferredInstance*,
+  element sx:slots { KvpSlot+ }?
+}
+
+DeferredInstance = element sx:deferredInstance {
+  element sx:last { GDate }?,
+  element sx:rem-occur { xsd:int },
+  element sx:instanceCount { xsd:int }
+}
+
+TemplateTransactions = element gnc:template-transactions {
+  Account*,
+  Transaction*
+}
+*/
+
+// ScheduledTransactions type is a list of ScheduledTransaction
+type ScheduledTransactions []*ScheduledTransaction
+
+// ScheduledTransaction type
+type ScheduledTransaction struct {
+	ID                types.GUID
+	Name              string
+	Enabled           bool
+	AutoCreate        bool
+	AutoCreateNotify  bool
+	AdvanceCreateDays int
+	AdvanceRemindDays int
+	InstanceCount     int
+	Start             types.GDate
+	Last              types.GDate
+	End               types.GDate
+	// NumOccur is the total number of occurrences, 0 if not limited.
+	NumOccur int
+	// RemOccur is the number of remaining occurrences.
+	RemOccur          int
+	Schedule          types.Recurrences
+	DeferredInstances []*DeferredInstance
+	Slots             Slots
+
+	// TemplateAccount is the template account of the template splits.
+	TemplateAccount *Account
+	// TemplateAccountID is the GUID of the template account, resolved
+	// once all the accounts of the book are read.
+	TemplateAccountID types.GUID
+	// Templates are the template transactions instantiated by the schedule.
+	Templates []*TemplateTransaction
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// DeferredInstance type is the state of an instance
+// created "since last run" but not yet committed.
+type DeferredInstance struct {
+	Last          types.GDate `xml:"last"`
+	RemOccur      int         `xml:"rem-occur"`
+	InstanceCount int         `xml:"instanceCount"`
+}
+
+// TemplateTransaction type is a transaction of the template accounts,
+// used as a model by a scheduled transaction.
+type TemplateTransaction struct {
+	Transaction *Transaction
+	Splits      []*TemplateSplit
+}
+
+// TemplateSplit type is a split of a template transaction,
+// with the real account and the amounts stored in the
+// "sched-xaction" slots by GnuCash.
+type TemplateSplit struct {
+	Split         *Split
+	Account       *Account
+	CreditFormula string
+	DebitFormula  string
+	Credit        types.Numeric
+	Debit         types.Numeric
+}
+
+// Amount returns the amount of the template split:
+// positive for a debit, negative for a credit.
+func (ts *TemplateSplit) Amount() *types.Numeric {
+	return types.Sub(&ts.Debit, &ts.Credit)
+}
+
+// yesNo converts a "y" / "n" value to bool.
+func yesNo(v string) (bool, error) {
+	switch v {
+	case "y":
+		return true, nil
+	case "n":
+		return false, nil
+	}
+	return false, fmt.Errorf("Invalid y/n value: %q", v)
+}
+
+func scheduledTransactionUnmarshalXML(decoder *xml.Decoder) (*ScheduledTransaction, error) {
+
+	var (
+		sx                                    ScheduledTransaction
+		enabled, autoCreate, autoCreateNotify string
+	)
+
+LOOP:
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			var v interface{}
+
+			switch se.Name.Local {
+			case "id":
+				v = &sx.ID
+			case "name":
+				v = &sx.Name
+			case "enabled":
+				v = &enabled
+			case "autoCreate":
+				v = &autoCreate
+			case "autoCreateNotify":
+				v = &autoCreateNotify
+			case "advanceCreateDays":
+				v = &sx.AdvanceCreateDays
+			case "advanceRemindDays":
+				v = &sx.AdvanceRemindDays
+			case "instanceCount":
+				v = &sx.InstanceCount
+			case "start":
+				v = &sx.Start
+			case "last":
+				v = &sx.Last
+			case "end":
+				v = &sx.End
+			case "num-occur":
+				v = &sx.NumOccur
+			case "rem-occur":
+				v = &sx.RemOccur
+			case "templ-acct":
+				v = &sx.TemplateAccountID
+			case "schedule":
+				var schedule struct {
+					Recurrences types.Recurrences `xml:"recurrence"`
+				}
+				if err := decoder.DecodeElement(&schedule, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				sx.Schedule = schedule.Recurrences
+			case "deferredInstance":
+				var di DeferredInstance
+				if err := decoder.DecodeElement(&di, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				sx.DeferredInstances = append(sx.DeferredInstances, &di)
+			case "slots":
+				v = &sx.Slots
+			default:
+				if err := sx.addElement(decoder, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+			}
+
+			if v != nil {
+				if err := decoder.DecodeElement(v, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+			}
+
+		case xml.EndElement:
+			if se.Name.Local == "schedxaction" {
+				break LOOP
+			}
+		}
+	}
+
+	var err error
+	if sx.Enabled, err = yesNo(enabled); err != nil {
+		return nil, err
+	}
+	if sx.AutoCreate, err = yesNo(autoCreate); err != nil {
+		return nil, err
+	}
+	if sx.AutoCreateNotify, err = yesNo(autoCreateNotify); err != nil {
+		return nil, err
+	}
+
+	return &sx, nil
+}
+
+// templateTransactionsUnmarshalXML reads a template-transactions element,
+// adding template accounts and transactions to the book.
+// The splits of the template transactions are looked up in the template
+// accounts and in the accounts: the template root may have been written
+// among the accounts, and moved to the template accounts only once all the
+// accounts are read.
+func templateTransactionsUnmarshalXML(decoder *xml.Decoder, book *Book, accMap, templAccMap AccountMap) error {
+	// splitAccMap has the accounts and the template accounts read so far
+	var splitAccMap AccountMap
+
+LOOP:
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			switch se.Name.Local {
+			case "account":
+				account, parentID, err := AccountUnmarshalXML(decoder, book.Commodities)
+				if err != nil {
+					return parseError(decoder, se.Name, err)
+				}
+				account.addAttrs(se.Attr)
+				if err := book.TemplateAccounts.insert(account, parentID, templAccMap); err != nil {
+					return parseError(decoder, se.Name, err)
+				}
+				splitAccMap = nil
+			case "transaction":
+				if splitAccMap == nil {
+					splitAccMap = AccountMap{}
+					for _, m := range []AccountMap{accMap, templAccMap} {
+						for id, acc := range m {
+							splitAccMap[id] = acc
+						}
+					}
+				}
+				trn, err := transactionUnmarshalXML(decoder, book.Commodities, splitAccMap, LotMap{})
+				if err != nil {
+					return parseError(decoder, se.Name, err)
+				}
+				trn.addAttrs(se.Attr)
+				book.TemplateTransactions.Add(trn)
+			}
+		case xml.EndElement:
+			if se.Name.Local == "template-transactions" {
+				break LOOP
+			}
+		}
+	}
+
+	return nil
+}
+
+// linkTemplates resolves the template account and the template
+// transactions of every scheduled transaction of the book, once all the
+// accounts are read. An error is returned for every template account
+// not found.
+func (b *Book) linkTemplates(templAccMap AccountMap) []error {
+	var errs []error
+	bySXAccount := map[*Account]*ScheduledTransaction{}
+	for _, sx := range b.ScheduledTransactions {
+		acc, ok := templAccMap[sx.TemplateAccountID]
+		if !ok {
+			errs = append(errs, fmt.Errorf("Scheduled transaction %s: template account not found: %s", sx.Name, sx.TemplateAccountID))
+			continue
+		}
+		sx.TemplateAccount = acc
+		bySXAccount[acc] = sx
+	}
+
+	for _, t := range b.TemplateTransactions {
+		var sx *ScheduledTransaction
+		tt := TemplateTransaction{Transaction: t}
+
+		for _, s := range t.Splits {
+			if x, ok := bySXAccount[s.Account]; ok {
+				sx = x
+			}
+			ts := TemplateSplit{
+				Split:         s,
+				Account:       b.AccountMap[s.Slots.GetGUID("sched-xaction/account")],
+				CreditFormula: s.Slots.GetString("sched-xaction/credit-formula"),
+				DebitFormula:  s.Slots.GetString("sched-xaction/debit-formula"),
+			}
+			if n := s.Slots.GetNumeric("sched-xaction/credit-numeric"); n != nil {
+				ts.Credit = *n
+			}
+			if n := s.Slots.GetNumeric("sched-xaction/debit-numeric"); n != nil {
+				ts.Debit = *n
+			}
+			tt.Splits = append(tt.Splits, &ts)
+		}
+		if sx != nil {
+			sx.Templates = append(sx.Templates, &tt)
+		}
+	}
+	return errs
+}
+
+// Occurrences returns the dates, between from and to (both included),
+// of the instances of the scheduled transaction still to be created,
+// i.e. those after the last occurrence, honoring the end date and the
+// number of remaining occurrences. The instances before from, overdue if
+// from is today, are not returned: a zero from returns all of them.
+func (sx *ScheduledTransaction) Occurrences(from, to time.Time) []time.Time {
+	var list []time.Time
+
+	after := time.Time(sx.Last)
+	if after.IsZero() {
+		after = time.Time(sx.Start).AddDate(0, 0, -1)
+	}
+	end := time.Time(sx.End)
+	remaining := sx.RemOccur
+
+	for d := sx.Schedule.Next(after); !d.IsZero() && !d.After(to); d = sx.Schedule.Next(d) {
+		if !end.IsZero() && d.After(end) {
+			break
+		}
+		if sx.NumOccur > 0 {
+			if remaining <= 0 {
+				break
+			}
+			remaining--
+		}
+		if !d.Before(from) {
+			list = append(list, d)
+		}
+	}
+	return list
+}
+
+// Add adds a scheduled transaction to the collection
+func (sxs *ScheduledTransactions) Add(sx *ScheduledTransaction) {
+	*sxs = append(*sxs, sx)
+}
+
+// Len returns the number of scheduled transactions.
+func (sxs ScheduledTransactions) Len() int {
+	return len(sxs)
+}
@@ -0,0 +1,228 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+const testScheduledBook = `<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Rent</act:name>
+  <act:id type="guid">000000000000000000000000000000c2</act:id>
+  <act:type>EXPENSE</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+<gnc:template-transactions>
+  <gnc:account version="2.0.0">
+    <act:name>Template Root</act:name>
+    <act:id type="guid">000000000000000000000000000000a0</act:id>
+    <act:type>ROOT</act:type>
+  </gnc:account>
+  <gnc:account version="2.0.0">
+    <act:name>000000000000000000000000000000b1</act:name>
+    <act:id type="guid">000000000000000000000000000000a1</act:id>
+    <act:type>BANK</act:type>
+    <act:commodity><cmdty:space>template</cmdty:space><cmdty:id>template</cmdty:id></act:commodity>
+    <act:commodity-scu>1</act:commodity-scu>
+    <act:parent type="guid">000000000000000000000000000000a0</act:parent>
+  </gnc:account>
+  <gnc:transaction version="2.0.0">
+    <trn:id type="guid">000000000000000000000000000000d1</trn:id>
+    <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+    <trn:date-posted><ts:date>2016-01-01 00:00:00 +0100</ts:date></trn:date-posted>
+    <trn:date-entered><ts:date>2016-01-01 10:59:00 +0100</ts:date></trn:date-entered>
+    <trn:description>Rent</trn:description>
+    <trn:splits>
+      <trn:split>
+        <split:id type="guid">000000000000000000000000000000e1</split:id>
+        <split:reconciled-state>n</split:reconciled-state>
+        <split:value>0/1</split:value>
+        <split:quantity>0/1</split:quantity>
+        <split:account type="guid">000000000000000000000000000000a1</split:account>
+        <split:slots>
+          <slot>
+            <slot:key>sched-xaction</slot:key>
+            <slot:value type="frame">
+              <slot><slot:key>account</slot:key><slot:value type="guid">000000000000000000000000000000c2</slot:value></slot>
+              <slot><slot:key>credit-formula</slot:key><slot:value type="string"></slot:value></slot>
+              <slot><slot:key>credit-numeric</slot:key><slot:value type="numeric">0/1</slot:value></slot>
+              <slot><slot:key>debit-formula</slot:key><slot:value type="string">750</slot:value></slot>
+              <slot><slot:key>debit-numeric</slot:key><slot:value type="numeric">750/1</slot:value></slot>
+            </slot:value>
+          </slot>
+        </split:slots>
+      </trn:split>
+      <trn:split>
+        <split:id type="guid">000000000000000000000000000000e2</split:id>
+        <split:reconciled-state>n</split:reconciled-state>
+        <split:value>0/1</split:value>
+        <split:quantity>0/1</split:quantity>
+        <split:account type="guid">000000000000000000000000000000a1</split:account>
+        <split:slots>
+          <slot>
+            <slot:key>sched-xaction</slot:key>
+            <slot:value type="frame">
+              <slot><slot:key>account</slot:key><slot:value type="guid">000000000000000000000000000000c1</slot:value></slot>
+              <slot><slot:key>credit-formula</slot:key><slot:value type="string">750</slot:value></slot>
+              <slot><slot:key>credit-numeric</slot:key><slot:value type="numeric">750/1</slot:value></slot>
+              <slot><slot:key>debit-formula</slot:key><slot:value type="string"></slot:value></slot>
+              <slot><slot:key>debit-numeric</slot:key><slot:value type="numeric">0/1</slot:value></slot>
+            </slot:value>
+          </slot>
+        </split:slots>
+      </trn:split>
+    </trn:splits>
+  </gnc:transaction>
+</gnc:template-transactions>
+<gnc:schedxaction version="2.0.0">
+  <sx:id type="guid">000000000000000000000000000000b1</sx:id>
+  <sx:name>Rent</sx:name>
+  <sx:enabled>y</sx:enabled>
+  <sx:autoCreate>n</sx:autoCreate>
+  <sx:autoCreateNotify>n</sx:autoCreateNotify>
+  <sx:advanceCreateDays>0</sx:advanceCreateDays>
+  <sx:advanceRemindDays>5</sx:advanceRemindDays>
+  <sx:instanceCount>3</sx:instanceCount>
+  <sx:start><gdate>2016-01-01</gdate></sx:start>
+  <sx:last><gdate>2016-03-01</gdate></sx:last>
+  <sx:num-occur>12</sx:num-occur>
+  <sx:rem-occur>9</sx:rem-occur>
+  <sx:templ-acct type="guid">000000000000000000000000000000a1</sx:templ-acct>
+  <sx:schedule>
+    <gnc:recurrence version="1.0.0">
+      <recurrence:mult>1</recurrence:mult>
+      <recurrence:period_type>month</recurrence:period_type>
+      <recurrence:start><gdate>2016-01-01</gdate></recurrence:start>
+      <recurrence:weekend_adj>forward</recurrence:weekend_adj>
+    </gnc:recurrence>
+  </sx:schedule>
+  <sx:deferredInstance>
+    <sx:last><gdate>2016-02-01</gdate></sx:last>
+    <sx:rem-occur>10</sx:rem-occur>
+    <sx:instanceCount>2</sx:instanceCount>
+  </sx:deferredInstance>
+</gnc:schedxaction>
+`
+
+func TestScheduledTransactions(t *testing.T) {
+	book := readTestBook(t, testScheduledBook)
+
+	if n := book.Accounts.Len(); n != 3 {
+		t.Errorf("Accounts: expected 3, got %d", n)
+	}
+	if n := book.TemplateAccounts.Len(); n != 2 {
+		t.Errorf("TemplateAccounts: expected 2, got %d", n)
+	}
+	if n := book.Transactions.Len(); n != 0 {
+		t.Errorf("Transactions: expected 0, got %d", n)
+	}
+	if n := book.ScheduledTransactions.Len(); n != 1 {
+		t.Fatalf("ScheduledTransactions: expected 1, got %d", n)
+	}
+
+	sx := book.ScheduledTransactions[0]
+	if sx.Name != "Rent" || !sx.Enabled || sx.AutoCreate || sx.AdvanceRemindDays != 5 {
+		t.Errorf("ScheduledTransaction: unexpected values %+v", sx)
+	}
+	if sx.Start.String() != "2016-01-01" || sx.Last.String() != "2016-03-01" || sx.End.String() != "" {
+		t.Errorf("ScheduledTransaction: unexpected dates %s %s %s", sx.Start, sx.Last, sx.End)
+	}
+	if sx.NumOccur != 12 || sx.RemOccur != 9 {
+		t.Errorf("ScheduledTransaction: unexpected occurrences %d %d", sx.NumOccur, sx.RemOccur)
+	}
+	if len(sx.DeferredInstances) != 1 || sx.DeferredInstances[0].RemOccur != 10 {
+		t.Errorf("ScheduledTransaction: unexpected deferred instances %v", sx.DeferredInstances)
+	}
+	if len(sx.Schedule) != 1 {
+		t.Fatalf("Schedule: expected 1 recurrence, got %d", len(sx.Schedule))
+	}
+	r := sx.Schedule[0]
+	if r.Mult != 1 || r.PeriodType != types.PeriodTypeMonth || r.WeekendAdjust != types.WeekendAdjustForward || r.Start.String() != "2016-01-01" {
+		t.Errorf("Recurrence: unexpected value %s", r)
+	}
+
+	if sx.TemplateAccount == nil || sx.TemplateAccount.Name != string(sx.ID) {
+		t.Errorf("TemplateAccount: unexpected account %v", sx.TemplateAccount)
+	}
+	if len(sx.Templates) != 1 || len(sx.Templates[0].Splits) != 2 {
+		t.Fatalf("Templates: expected 1 transaction with 2 splits")
+	}
+	var testCases = []struct {
+		account string
+		amount  string
+		formula string
+	}{
+		{"Rent", "750", "750"},
+		{"Bank", "-750", ""},
+	}
+	for j, tc := range testCases {
+		ts := sx.Templates[0].Splits[j]
+		if ts.Account == nil || ts.Account.Name != tc.account {
+			t.Errorf("TemplateSplit[%d]: expected account %s, got %v", j, tc.account, ts.Account)
+		}
+		if ts.Amount().String() != tc.amount {
+			t.Errorf("TemplateSplit[%d]: expected amount %s, got %s", j, tc.amount, ts.Amount())
+		}
+		if ts.DebitFormula != tc.formula {
+			t.Errorf("TemplateSplit[%d]: expected debit formula %q, got %q", j, tc.formula, ts.DebitFormula)
+		}
+	}
+}
+
+func TestScheduledTransactionsTemplateRootInAccounts(t *testing.T) {
+	// the template accounts written among the accounts
+	j := strings.Index(testScheduledBook, "<gnc:template-transactions>")
+	k := strings.Index(testScheduledBook, "  <gnc:transaction")
+	templAccounts := testScheduledBook[j+len("<gnc:template-transactions>\n") : k]
+	body := testScheduledBook[:j] + templAccounts + "<gnc:template-transactions>\n" + testScheduledBook[k:]
+
+	book := readTestBook(t, body)
+	if n := book.Accounts.Len(); n != 3 {
+		t.Errorf("Accounts: expected 3, got %d", n)
+	}
+	if n := book.TemplateAccounts.Len(); n != 2 {
+		t.Errorf("TemplateAccounts: expected 2, got %d", n)
+	}
+	if n := book.ScheduledTransactions.Len(); n != 1 {
+		t.Fatalf("ScheduledTransactions: expected 1, got %d", n)
+	}
+	sx := book.ScheduledTransactions[0]
+	if sx.TemplateAccount == nil || sx.TemplateAccount.Name != string(sx.ID) {
+		t.Errorf("TemplateAccount: unexpected account %v", sx.TemplateAccount)
+	}
+	if len(sx.Templates) != 1 || len(sx.Templates[0].Splits) != 2 {
+		t.Errorf("Templates: expected 1 transaction with 2 splits")
+	}
+}
+
+func TestScheduledTransactionsTemplateAccountNotFound(t *testing.T) {
+	body := strings.Replace(testScheduledBook, "a1</sx:templ-acct>", "a9</sx:templ-acct>", 1)
+	s := testXMLHeader + body + testXMLFooter
+
+	if _, err := Read(strings.NewReader(s)); err == nil || !strings.Contains(err.Error(), "template account not found") {
+		t.Errorf("strict: unexpected error %v", err)
+	}
+	gnc, err := ReadWithOptions(strings.NewReader(s), &ReadOptions{Lenient: true})
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %s", err)
+	}
+	if len(gnc.Warnings) != 1 || gnc.Book.ScheduledTransactions[0].TemplateAccount != nil {
+		t.Errorf("lenient: expected 1 warning, got %v", gnc.Warnings)
+	}
+}
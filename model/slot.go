@@ -0,0 +1,360 @@
+package model
+
+import (
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+KvpSlot = element slot {
+  element slot:key { text },
+  KvpValue
+}
+
+KvpValue = ( element slot:value { attribute type { "integer" }, xsd:int }
+           | element slot:value { attribute type { "double" }, xsd:double }
+           | element slot:value { attribute type { "numeric" }, GncNumeric }
+           | element slot:value { attribute type { "string" }, text }
+           | element slot:value { attribute type { "guid" }, GUID }
+           | element slot:value { attribute type { "timespec" }, TimeSpec }
+           | element slot:value { attribute type { "gdate" }, GDate }
+           | element slot:value { attribute type { "binary" }, xsd:string { pattern = "[0-9a-f]*" }}
+           | element slot:value { attribute type { "list" }, KvpValue* }
+           | element slot:value { attribute type { "frame" }, KvpSlot* }
+           )
+*/
+
+// KvpType enum type
+type KvpType int
+
+// KvpType constants
+const (
+	KvpTypeInvalid KvpType = iota
+	KvpTypeInteger
+	KvpTypeDouble
+	KvpTypeNumeric
+	KvpTypeString
+	KvpTypeGUID
+	KvpTypeTimespec
+	KvpTypeGDate
+	KvpTypeBinary
+	KvpTypeList
+	KvpTypeFrame
+)
+
+var kvpTypeLabels = []string{"", "integer", "double", "numeric", "string", "guid", "timespec", "gdate", "binary", "list", "frame"}
+
+// KvpTypeFromString returns the KvpType of the "type" attribute value.
+func KvpTypeFromString(v string) (KvpType, error) {
+	for j, label := range kvpTypeLabels {
+		if j > 0 && label == v {
+			return KvpType(j), nil
+		}
+	}
+	return KvpTypeInvalid, fmt.Errorf("Invalid KvpType: %q", v)
+}
+
+func (kt KvpType) String() string {
+	if kt < 0 || int(kt) >= len(kvpTypeLabels) {
+		return ""
+	}
+	return kvpTypeLabels[kt]
+}
+
+// Slots type is a list of Slot.
+type Slots []*Slot
+
+// Slot type is a key-value pair.
+type Slot struct {
+	Key   string
+	Value *KvpValue
+}
+
+// KvpValue type is a typed value of a slot.
+// Only the field corresponding to Type is meaningful.
+type KvpValue struct {
+	Type     KvpType
+	Integer  int64
+	Double   float64
+	Numeric  types.Numeric
+	Text     string
+	GUID     types.GUID
+	Timespec types.Timespec
+	GDate    types.GDate
+	Binary   []byte
+	List     []*KvpValue
+	Frame    Slots
+}
+
+// UnmarshalXML implements xml.Unmarshaler interface.
+// The start element is the container of the slots (ex: act:slots).
+func (ss *Slots) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	slots, err := slotsUnmarshalXML(decoder, start.Name.Local)
+	if err != nil {
+		return err
+	}
+	*ss = slots
+	return nil
+}
+
+// slotsUnmarshalXML reads the slot elements until the end element
+// with the given local name.
+func slotsUnmarshalXML(decoder *xml.Decoder, end string) (Slots, error) {
+	var slots Slots
+
+	for {
+		// Read tokens from the XML document in a stream.
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		// Inspect the type of the token just read.
+		switch se := token.(type) {
+		case xml.StartElement:
+			if se.Name.Local == "slot" {
+				s, err := slotUnmarshalXML(decoder)
+				if err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				slots = append(slots, s)
+			} else if err := decoder.Skip(); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if se.Name.Local == end {
+				return slots, nil
+			}
+		}
+	}
+}
+
+func slotUnmarshalXML(decoder *xml.Decoder) (*Slot, error) {
+	var slot Slot
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch se := token.(type) {
+		case xml.StartElement:
+			switch se.Name.Local {
+			case "key":
+				if err := decoder.DecodeElement(&slot.Key, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+			case "value":
+				var v KvpValue
+				if err := decoder.DecodeElement(&v, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				slot.Value = &v
+			default:
+				if err := decoder.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			if se.Name.Local == "slot" {
+				if slot.Value == nil {
+					return nil, fmt.Errorf("Slot without value: key=%q", slot.Key)
+				}
+				return &slot, nil
+			}
+		}
+	}
+}
+
+// UnmarshalXML implements xml.Unmarshaler interface
+func (v *KvpValue) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) error {
+	var (
+		attrType string
+		err      error
+	)
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "type" {
+			attrType = attr.Value
+		}
+	}
+	if v.Type, err = KvpTypeFromString(attrType); err != nil {
+		return err
+	}
+
+	switch v.Type {
+	case KvpTypeTimespec:
+		return decoder.DecodeElement(&v.Timespec, &start)
+	case KvpTypeGDate:
+		return decoder.DecodeElement(&v.GDate, &start)
+	case KvpTypeList:
+		return v.unmarshalList(decoder)
+	case KvpTypeFrame:
+		v.Frame, err = slotsUnmarshalXML(decoder, start.Name.Local)
+		return err
+	}
+
+	var text string
+	if err = decoder.DecodeElement(&text, &start); err != nil {
+		return err
+	}
+	switch v.Type {
+	case KvpTypeInteger:
+		v.Integer, err = strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	case KvpTypeDouble:
+		v.Double, err = strconv.ParseFloat(strings.TrimSpace(text), 64)
+	case KvpTypeNumeric:
+		var n *types.Numeric
+		if n, err = types.FromString(strings.TrimSpace(text)); err == nil {
+			v.Numeric = *n
+		}
+	case KvpTypeString:
+		v.Text = text
+	case KvpTypeGUID:
+		v.GUID = types.GUID(strings.TrimSpace(text))
+	case KvpTypeBinary:
+		v.Binary, err = hex.DecodeString(strings.TrimSpace(text))
+	}
+	return err
+}
+
+func (v *KvpValue) unmarshalList(decoder *xml.Decoder) error {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch se := token.(type) {
+		case xml.StartElement:
+			if se.Name.Local != "value" {
+				if err := decoder.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			var item KvpValue
+			if err := decoder.DecodeElement(&item, &se); err != nil {
+				return parseError(decoder, se.Name, err)
+			}
+			v.List = append(v.List, &item)
+		case xml.EndElement:
+			// the end of the list value: nested values are consumed by DecodeElement
+			return nil
+		}
+	}
+}
+
+// String returns the string representation of the value.
+func (v *KvpValue) String() string {
+	if v == nil {
+		return ""
+	}
+	switch v.Type {
+	case KvpTypeInteger:
+		return strconv.FormatInt(v.Integer, 10)
+	case KvpTypeDouble:
+		return strconv.FormatFloat(v.Double, 'g', -1, 64)
+	case KvpTypeNumeric:
+		return v.Numeric.String()
+	case KvpTypeString:
+		return v.Text
+	case KvpTypeGUID:
+		return string(v.GUID)
+	case KvpTypeTimespec:
+		return v.Timespec.String()
+	case KvpTypeGDate:
+		return v.GDate.String()
+	case KvpTypeBinary:
+		return hex.EncodeToString(v.Binary)
+	case KvpTypeList:
+		items := make([]string, len(v.List))
+		for j, item := range v.List {
+			items[j] = item.String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case KvpTypeFrame:
+		return v.Frame.String()
+	}
+	return ""
+}
+
+// Get returns the value identified by path, or nil if not found.
+// The path is made of slot keys separated by "/", where every key but
+// the last one identifies a frame: for example "hbci/account-id".
+func (ss Slots) Get(path string) *KvpValue {
+	keys := strings.Split(path, "/")
+	frame := ss
+	for j, key := range keys {
+		s := frame.find(key)
+		if s == nil {
+			return nil
+		}
+		if j == len(keys)-1 {
+			return s.Value
+		}
+		if s.Value.Type != KvpTypeFrame {
+			return nil
+		}
+		frame = s.Value.Frame
+	}
+	return nil
+}
+
+// GetString returns the string value identified by path.
+// It returns "" if the value is not found or it is not a string.
+func (ss Slots) GetString(path string) string {
+	if v := ss.Get(path); v != nil && v.Type == KvpTypeString {
+		return v.Text
+	}
+	return ""
+}
+
+// GetGUID returns the guid value identified by path.
+// It returns "" if the value is not found or it is not a guid.
+func (ss Slots) GetGUID(path string) types.GUID {
+	if v := ss.Get(path); v != nil && v.Type == KvpTypeGUID {
+		return v.GUID
+	}
+	return ""
+}
+
+// GetNumeric returns the numeric value identified by path.
+// It returns nil if the value is not found or it is not a numeric.
+func (ss Slots) GetNumeric(path string) *types.Numeric {
+	if v := ss.Get(path); v != nil && v.Type == KvpTypeNumeric {
+		return types.Copy(&v.Numeric)
+	}
+	return nil
+}
+
+// GetBool returns true if the value identified by path is
+// the string "true", as GnuCash stores boolean flags.
+func (ss Slots) GetBool(path string) bool {
+	return ss.GetString(path) == "true"
+}
+
+// Len returns the number of slots.
+func (ss Slots) Len() int {
+	return len(ss)
+}
+
+func (ss Slots) find(key string) *Slot {
+	for _, s := range ss {
+		if s.Key == key {
+			return s
+		}
+	}
+	return nil
+}
+
+// String returns the string representation of the slots.
+func (ss Slots) String() string {
+	items := make([]string, len(ss))
+	for j, s := range ss {
+		items[j] = s.Key + "=" + s.Value.String()
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
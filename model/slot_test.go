@@ -0,0 +1,116 @@
+package model
+
+import (
+	"testing"
+)
+
+const testSlotsBook = `<book:slots>
+  <slot>
+    <slot:key>features</slot:key>
+    <slot:value type="frame">
+      <slot>
+        <slot:key>Register sort and filter settings stored in .gcm file</slot:key>
+        <slot:value type="string">Store the register sort and filter settings in .gcm metadata file (requires at least GnuCash 3.3)</slot:value>
+      </slot>
+    </slot:value>
+  </slot>
+</book:slots>
+<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>BANK</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:commodity-scu>100</act:commodity-scu>
+  <act:slots>
+    <slot>
+      <slot:key>notes</slot:key>
+      <slot:value type="string">main account</slot:value>
+    </slot>
+    <slot>
+      <slot:key>placeholder</slot:key>
+      <slot:value type="string">true</slot:value>
+    </slot>
+    <slot>
+      <slot:key>hbci</slot:key>
+      <slot:value type="frame">
+        <slot>
+          <slot:key>account-id</slot:key>
+          <slot:value type="string">12345</slot:value>
+        </slot>
+        <slot>
+          <slot:key>trans-retrieval</slot:key>
+          <slot:value type="gdate"><gdate>2016-05-01</gdate></slot:value>
+        </slot>
+      </slot:value>
+    </slot>
+    <slot>
+      <slot:key>values</slot:key>
+      <slot:value type="list">
+        <slot:value type="integer">42</slot:value>
+        <slot:value type="numeric">-250/100</slot:value>
+        <slot:value type="double">1.5</slot:value>
+        <slot:value type="binary">0aff</slot:value>
+      </slot:value>
+    </slot>
+    <slot>
+      <slot:key>last-num</slot:key>
+      <slot:value type="timespec"><ts:date>2016-05-01 10:59:00 +0200</ts:date></slot:value>
+    </slot>
+  </act:slots>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+</gnc:account>
+`
+
+func TestSlots(t *testing.T) {
+	book := readTestBook(t, testSlotsBook)
+
+	if features := book.Features(); len(features) != 1 {
+		t.Errorf("Features: expected 1 feature, got %v", features)
+	}
+
+	acc := book.Accounts.List[1]
+	if acc.Notes() != "main account" {
+		t.Errorf("Notes: expected %q, got %q", "main account", acc.Notes())
+	}
+	if !acc.Placeholder() {
+		t.Errorf("Placeholder: expected true")
+	}
+	if acc.Hidden() {
+		t.Errorf("Hidden: expected false")
+	}
+
+	var testCases = []struct {
+		path     string
+		kvpType  KvpType
+		expected string
+	}{
+		{"hbci/account-id", KvpTypeString, "12345"},
+		{"hbci/trans-retrieval", KvpTypeGDate, "2016-05-01"},
+		{"values", KvpTypeList, "[42, -250/100, 1.5, 0aff]"},
+		{"last-num", KvpTypeTimespec, "2016-05-01 10:59:00 +0200 +0200"},
+		{"hbci", KvpTypeFrame, "{account-id=12345, trans-retrieval=2016-05-01}"},
+		{"hbci/missing", KvpTypeInvalid, ""},
+		{"notes/missing", KvpTypeInvalid, ""},
+	}
+
+	for _, tc := range testCases {
+		v := acc.Slots.Get(tc.path)
+		if v == nil {
+			if tc.kvpType != KvpTypeInvalid {
+				t.Errorf("Get(%q): unexpected nil value", tc.path)
+			}
+			continue
+		}
+		if v.Type != tc.kvpType {
+			t.Errorf("Get(%q): expected type %s, got %s", tc.path, tc.kvpType, v.Type)
+		}
+		if v.String() != tc.expected {
+			t.Errorf("Get(%q): expected %q, got %q", tc.path, tc.expected, v.String())
+		}
+	}
+}
@@ -12,14 +12,25 @@ type Splits []*Split
 
 // Split type
 type Split struct {
+	ID              types.GUID            `xml:"id"`
 	ReconciledState types.ReconciledState `xml:"reconciled-state"`
 	ReconcileDate   types.Timespec        `xml:"reconcile-date"`
 	Value           types.Numeric         `xml:"value"`
 	Memo            string                `xml:"memo"`
+	Action          string                `xml:"action"`
 	Quantity        types.Numeric         `xml:"quantity"`
+	Slots           Slots                 `xml:"slots"`
 	Account         *Account              `xml:"-"`
+	Transaction     *Transaction          `xml:"-"`
+	Lot             *Lot                  `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
 }
 
+// SplitMap maps the GUID of a split to the split.
+type SplitMap map[types.GUID]*Split
+
 // Add adds a split to the collection
 func (ss *Splits) Add(s *Split) {
 	*ss = append(*ss, s)
@@ -30,23 +41,24 @@ func (ss Splits) Len() int {
 	return len(ss)
 }
 
-func SplitsUnmarshalXML(decoder *xml.Decoder, splits *Splits, accMap AccountMap) error {
+func SplitsUnmarshalXML(decoder *xml.Decoder, splits *Splits, accMap AccountMap, lotMap LotMap) error {
 
 LOOP:
 	for {
 		// Read tokens from the XML document in a stream.
-		token, _ := decoder.Token()
-		if token == nil {
-			break LOOP
+		token, err := decoder.Token()
+		if err != nil {
+			return err
 		}
 		// Inspect the type of the token just read.
 		switch se := token.(type) {
 		case xml.StartElement:
 			if se.Name.Local == "split" {
-				s, err := SplitUnmarshalXML(decoder, accMap)
+				s, err := SplitUnmarshalXML(decoder, accMap, lotMap)
 				if err != nil {
-					return err
+					return parseError(decoder, se.Name, err)
 				}
+				s.addAttrs(se.Attr)
 				splits.Add(s)
 			}
 		case xml.EndElement:
@@ -59,19 +71,20 @@ LOOP:
 	return nil
 }
 
-func SplitUnmarshalXML(decoder *xml.Decoder, accMap AccountMap) (*Split, error) {
+func SplitUnmarshalXML(decoder *xml.Decoder, accMap AccountMap, lotMap LotMap) (*Split, error) {
 
 	var (
 		split     Split
-		accountID string
+		accountID types.GUID
+		lotID     types.GUID
 	)
 
 LOOP:
 	for {
 		// Read tokens from the XML document in a stream.
-		token, _ := decoder.Token()
-		if token == nil {
-			break LOOP
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
 		}
 		// Inspect the type of the token just read.
 		switch se := token.(type) {
@@ -79,6 +92,8 @@ LOOP:
 			var v interface{}
 
 			switch se.Name.Local {
+			case "id":
+				v = &split.ID
 			case "reconciled-state":
 				v = &split.ReconciledState
 			case "reconcile-date":
@@ -87,23 +102,39 @@ LOOP:
 				v = &split.Value
 			case "memo":
 				v = &split.Memo
+			case "action":
+				v = &split.Action
 			case "quantity":
 				v = &split.Quantity
+			case "slots":
+				v = &split.Slots
 			case "account":
 				v = &accountID
+			case "lot":
+				v = &lotID
+			default:
+				if err := split.addElement(decoder, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
 			}
 
 			if v != nil {
 				if err := decoder.DecodeElement(v, &se); err != nil {
-					return nil, err
+					return nil, parseError(decoder, se.Name, err)
 				}
 				switch se.Name.Local {
 				case "account":
 					acc, ok := accMap[accountID]
 					if !ok {
-						return nil, fmt.Errorf("Account not found: %s", accountID)
+						return nil, parseError(decoder, se.Name, fmt.Errorf("Account not found: %s", accountID))
 					}
 					split.Account = acc
+				case "lot":
+					lot, ok := lotMap[lotID]
+					if !ok {
+						return nil, parseError(decoder, se.Name, fmt.Errorf("Lot not found: %s", lotID))
+					}
+					split.Lot = lot
 				}
 			}
 
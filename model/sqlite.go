@@ -0,0 +1,609 @@
+package model
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+
+	// register the "sqlite3" database/sql driver
+	_ "github.com/mattn/go-sqlite3"
+)
+
+/*
+GnuCash SQL schema, as created by src/backend/sql/gnc-*-sql.cpp
+(only the tables read by ReadSQLiteFile are listed).
+
+books        (guid, root_account_guid, root_template_guid)
+commodities  (guid, namespace, mnemonic, fullname, cusip, fraction,
+              quote_flag, quote_source, quote_tz)
+accounts     (guid, name, account_type, commodity_guid, commodity_scu,
+              non_std_scu, parent_guid, code, description, hidden, placeholder)
+transactions (guid, currency_guid, num, post_date, enter_date, description)
+splits       (guid, tx_guid, account_guid, memo, action, reconcile_state,
+              reconcile_date, value_num, value_denom, quantity_num,
+              quantity_denom, lot_guid)
+prices       (guid, commodity_guid, currency_guid, date, source, type,
+              value_num, value_denom)
+lots         (guid, account_guid, is_closed)
+slots        (id, obj_guid, name, slot_type, int64_val, string_val,
+              double_val, timespec_val, guid_val, numeric_val_num,
+              numeric_val_denom, gdate_val)
+*/
+
+// sqliteMagic is the header of a SQLite 3 database file.
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+// sqlSlotTypes maps the slot_type column values to KvpType.
+var sqlSlotTypes = map[int64]KvpType{
+	1:  KvpTypeInteger,
+	2:  KvpTypeDouble,
+	3:  KvpTypeNumeric,
+	4:  KvpTypeString,
+	5:  KvpTypeGUID,
+	6:  KvpTypeTimespec,
+	7:  KvpTypeBinary,
+	8:  KvpTypeList,
+	9:  KvpTypeFrame,
+	10: KvpTypeGDate,
+}
+
+// sqlTimeForms are the layouts of the dates stored by the SQL backend:
+// GnuCash 3 and later, and GnuCash 2.6.
+var sqlTimeForms = []string{"2006-01-02 15:04:05", "20060102150405"}
+
+// sqliteBackend type is the SQLite backend.
+type sqliteBackend struct{}
+
+func init() {
+	Register(sqliteBackend{})
+}
+
+// Format returns "sqlite".
+func (sqliteBackend) Format() string {
+	return "sqlite"
+}
+
+// Capabilities implements Backend interface.
+func (sqliteBackend) Capabilities() Capabilities {
+	return CanRead
+}
+
+// Detect implements Backend interface.
+func (sqliteBackend) Detect(header []byte) bool {
+	return bytes.HasPrefix(header, sqliteMagic)
+}
+
+// Load implements Backend interface. The options are ignored.
+func (sqliteBackend) Load(path string, opts *ReadOptions) (*Gnc, error) {
+	return ReadSQLiteFile(path)
+}
+
+// ReadSQLiteFile reads the gnucash book saved in SQLite format.
+//
+// Books, commodities, accounts, transactions, splits, prices, lots and
+// their slots are read; scheduled transactions, budgets and business
+// objects are not.
+func ReadSQLiteFile(path string) (*Gnc, error) {
+	// the path is escaped, so that "?" and "#" are not taken
+	// as the query and the fragment of the URI
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?mode=ro"
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	r := sqlReader{db: db}
+	book, err := r.book()
+	if err != nil {
+		return nil, err
+	}
+	return &Gnc{Book: book, Warnings: r.warnings}, nil
+}
+
+// sqlSlot type is a row of the slots table.
+type sqlSlot struct {
+	name        string
+	slotType    int64
+	int64Val    sql.NullInt64
+	stringVal   sql.NullString
+	doubleVal   sql.NullFloat64
+	timespecVal sql.NullString
+	guidVal     sql.NullString
+	numericNum  sql.NullInt64
+	numericDen  sql.NullInt64
+	gdateVal    sql.NullString
+}
+
+// sqlReader reads the tables of a GnuCash SQL database.
+type sqlReader struct {
+	db *sql.DB
+	// slots by obj_guid
+	slots map[types.GUID][]*sqlSlot
+	// commodities by guid
+	commodities map[types.GUID]*Commodity
+	// problems not stopping the reading
+	warnings []error
+}
+
+func (r *sqlReader) book() (*Book, error) {
+	var (
+		book                 Book
+		rootID, templateID   string
+		accMap, templAccMap  = AccountMap{}, AccountMap{}
+		lotMap               = LotMap{}
+		templateTransactions Transactions
+		err                  error
+	)
+
+	row := r.db.QueryRow("SELECT guid, root_account_guid, root_template_guid FROM books")
+	if err = row.Scan(&book.ID, &rootID, &templateID); err != nil {
+		return nil, fmt.Errorf("Book not found: %s", err)
+	}
+	if err = r.readSlots(); err != nil {
+		return nil, err
+	}
+	if book.Slots, err = r.objSlots(book.ID); err != nil {
+		return nil, err
+	}
+	if err = r.readCommodities(&book); err != nil {
+		return nil, err
+	}
+	if err = r.readAccounts(&book, types.GUID(rootID), types.GUID(templateID), accMap, templAccMap); err != nil {
+		return nil, err
+	}
+	if err = r.readLots(accMap, lotMap); err != nil {
+		return nil, err
+	}
+	if templateTransactions, err = r.readTransactions(&book, accMap, templAccMap, lotMap); err != nil {
+		return nil, err
+	}
+	if err = r.readPrices(&book); err != nil {
+		return nil, err
+	}
+
+	book.Transactions.Sort()
+	book.TemplateTransactions = templateTransactions
+	book.AccountMap = accMap
+	book.LotMap = lotMap
+	book.indexTransactions()
+	// the scheduled transactions and the business objects are not read
+	r.warnings = append(r.warnings, book.linkTemplates(templAccMap)...)
+	return &book, nil
+}
+
+// readSlots reads the whole slots table, indexed by object GUID.
+func (r *sqlReader) readSlots() error {
+	rows, err := r.db.Query(`SELECT obj_guid, name, slot_type, int64_val, string_val,
+		double_val, timespec_val, guid_val, numeric_val_num, numeric_val_denom, gdate_val
+		FROM slots ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	r.slots = map[types.GUID][]*sqlSlot{}
+	for rows.Next() {
+		var (
+			obj types.GUID
+			s   sqlSlot
+		)
+		if err := rows.Scan(&obj, &s.name, &s.slotType, &s.int64Val, &s.stringVal,
+			&s.doubleVal, &s.timespecVal, &s.guidVal, &s.numericNum, &s.numericDen, &s.gdateVal); err != nil {
+			return err
+		}
+		r.slots[obj] = append(r.slots[obj], &s)
+	}
+	return rows.Err()
+}
+
+// objSlots returns the slots of the object with the given GUID.
+// The name of a slot is its full path: the key is its last part.
+func (r *sqlReader) objSlots(obj types.GUID) (Slots, error) {
+	var slots Slots
+	for _, s := range r.slots[obj] {
+		v, err := r.kvpValue(s)
+		if err != nil {
+			return nil, err
+		}
+		key := s.name
+		if j := strings.LastIndexByte(key, '/'); j >= 0 {
+			key = key[j+1:]
+		}
+		slots = append(slots, &Slot{Key: key, Value: v})
+	}
+	return slots, nil
+}
+
+// kvpValue returns the value of a slot row. The values of frames and
+// lists are the slots of the object with GUID guid_val.
+func (r *sqlReader) kvpValue(s *sqlSlot) (*KvpValue, error) {
+	typ, ok := sqlSlotTypes[s.slotType]
+	if !ok {
+		return nil, fmt.Errorf("Invalid slot type %d: name=%q", s.slotType, s.name)
+	}
+	v := KvpValue{Type: typ}
+
+	switch typ {
+	case KvpTypeInteger:
+		v.Integer = s.int64Val.Int64
+	case KvpTypeDouble:
+		v.Double = s.doubleVal.Float64
+	case KvpTypeNumeric:
+		v.Numeric = *types.FromInt64(s.numericNum.Int64, s.numericDen.Int64)
+	case KvpTypeString:
+		v.Text = s.stringVal.String
+	case KvpTypeGUID:
+		v.GUID = types.GUID(s.guidVal.String)
+	case KvpTypeTimespec:
+		t, err := parseSQLTime(s.timespecVal.String)
+		if err != nil {
+			return nil, err
+		}
+		v.Timespec = types.Timespec(t)
+	case KvpTypeGDate:
+		t, err := time.Parse("20060102", strings.Replace(s.gdateVal.String, "-", "", -1))
+		if err != nil {
+			return nil, err
+		}
+		v.GDate = types.GDate(t)
+	case KvpTypeList:
+		for _, item := range r.slots[types.GUID(s.guidVal.String)] {
+			x, err := r.kvpValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v.List = append(v.List, x)
+		}
+	case KvpTypeFrame:
+		frame, err := r.objSlots(types.GUID(s.guidVal.String))
+		if err != nil {
+			return nil, err
+		}
+		v.Frame = frame
+	}
+	return &v, nil
+}
+
+// parseSQLTime parses a date stored by the SQL backend, in UTC.
+// An empty value is the zero time.
+func parseSQLTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	var err error
+	for _, form := range sqlTimeForms {
+		var t time.Time
+		if t, err = time.Parse(form, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func (r *sqlReader) readCommodities(book *Book) error {
+	rows, err := r.db.Query(`SELECT guid, namespace, mnemonic, fullname, cusip, fraction,
+		quote_flag, quote_source, quote_tz FROM commodities`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	r.commodities = map[types.GUID]*Commodity{}
+	for rows.Next() {
+		var (
+			c                                 Commodity
+			name, xcode, quoteSource, quoteTz sql.NullString
+			fraction, quoteFlag               int64
+		)
+		if err := rows.Scan(&c.GUID, &c.Space, &c.ID, &name, &xcode, &fraction,
+			&quoteFlag, &quoteSource, &quoteTz); err != nil {
+			return err
+		}
+		c.Name = name.String
+		c.Xcode = xcode.String
+		c.Fraction = strconv.FormatInt(fraction, 10)
+		c.GetQuotes = quoteFlag != 0
+		c.QuoteSource = quoteSource.String
+		if quoteTz.Valid {
+			c.QuoteTz = &quoteTz.String
+		}
+		if c.Slots, err = r.objSlots(c.GUID); err != nil {
+			return err
+		}
+		book.Commodities.Add(&c)
+		r.commodities[c.GUID] = &c
+	}
+	return rows.Err()
+}
+
+// commodity returns the commodity with the given GUID.
+// A NULL GUID is a nil commodity.
+func (r *sqlReader) commodity(id sql.NullString) (*Commodity, error) {
+	if !id.Valid || id.String == "" {
+		return nil, nil
+	}
+	c, ok := r.commodities[types.GUID(id.String)]
+	if !ok {
+		return nil, fmt.Errorf("Commodity not found: %s", id.String)
+	}
+	return c, nil
+}
+
+// readAccounts reads the accounts table, inserting the accounts of the
+// tree of templateID in the template accounts and the others in the book
+// accounts, whose root must be rootID.
+func (r *sqlReader) readAccounts(book *Book, rootID, templateID types.GUID, accMap, templAccMap AccountMap) error {
+	rows, err := r.db.Query(`SELECT guid, name, account_type, commodity_guid, commodity_scu,
+		non_std_scu, parent_guid, code, description, hidden, placeholder FROM accounts`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var list []*Account
+	all := map[types.GUID]*Account{}
+	children := map[types.GUID][]*Account{}
+	for rows.Next() {
+		var (
+			acc                                  Account
+			accType                              string
+			cmdtyID, parentID, code, description sql.NullString
+			nonStdScu, hidden, placeholder       sql.NullInt64
+		)
+		if err := rows.Scan(&acc.ID, &acc.Name, &accType, &cmdtyID, &acc.CommodityScu,
+			&nonStdScu, &parentID, &code, &description, &hidden, &placeholder); err != nil {
+			return err
+		}
+		acc.Type = types.AccountTypeFromString(accType)
+		acc.NonStandardScu = nonStdScu.Int64 != 0
+		acc.Code = code.String
+		acc.Description = description.String
+		if acc.Currency, err = r.commodity(cmdtyID); err != nil {
+			return err
+		}
+		if acc.Slots, err = r.objSlots(acc.ID); err != nil {
+			return err
+		}
+		// the XML backend keeps the flags in the account slots
+		for _, flag := range []struct {
+			key string
+			set bool
+		}{{"hidden", hidden.Int64 != 0}, {"placeholder", placeholder.Int64 != 0}} {
+			if flag.set && acc.Slots.Get(flag.key) == nil {
+				acc.Slots = append(acc.Slots, &Slot{Key: flag.key, Value: &KvpValue{Type: KvpTypeString, Text: "true"}})
+			}
+		}
+		acc.ParentID = types.GUID(parentID.String)
+		list = append(list, &acc)
+		all[acc.ID] = &acc
+		children[acc.ParentID] = append(children[acc.ParentID], &acc)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := all[rootID]; !ok {
+		return fmt.Errorf("Root account not found: %s", rootID)
+	}
+	// the accounts of the tree of templateID
+	templates := map[*Account]bool{}
+	var mark func(id types.GUID)
+	mark = func(id types.GUID) {
+		if acc, ok := all[id]; ok && !templates[acc] {
+			templates[acc] = true
+			for _, child := range children[id] {
+				mark(child.ID)
+			}
+		}
+	}
+	mark(templateID)
+
+	for _, acc := range list {
+		accounts, m := &book.Accounts, accMap
+		if templates[acc] {
+			accounts, m = &book.TemplateAccounts, templAccMap
+		}
+		if err := accounts.insert(acc, acc.ParentID, m); err != nil {
+			return err
+		}
+	}
+	r.warnings = append(r.warnings, book.resolveAccounts(accMap, templAccMap)...)
+	return nil
+}
+
+func (r *sqlReader) readLots(accMap AccountMap, lotMap LotMap) error {
+	rows, err := r.db.Query("SELECT guid, account_guid FROM lots")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			lot   Lot
+			accID sql.NullString
+		)
+		if err := rows.Scan(&lot.ID, &accID); err != nil {
+			return err
+		}
+		if lot.Slots, err = r.objSlots(lot.ID); err != nil {
+			return err
+		}
+		if acc, ok := accMap[types.GUID(accID.String)]; ok {
+			lot.Account = acc
+			acc.Lots.Add(&lot)
+		}
+		lotMap[lot.ID] = &lot
+	}
+	return rows.Err()
+}
+
+// readTransactions reads the transactions and their splits, adding
+// to the book those of the book accounts. The transactions of the
+// template accounts are returned.
+func (r *sqlReader) readTransactions(book *Book, accMap, templAccMap AccountMap, lotMap LotMap) (Transactions, error) {
+	rows, err := r.db.Query(`SELECT guid, currency_guid, num, post_date, enter_date, description
+		FROM transactions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	trnMap := TransactionMap{}
+	var list Transactions
+	for rows.Next() {
+		var (
+			trn                                        Transaction
+			cmdtyID, num, posted, entered, description sql.NullString
+		)
+		if err := rows.Scan(&trn.ID, &cmdtyID, &num, &posted, &entered, &description); err != nil {
+			return nil, err
+		}
+		trn.Num = num.String
+		trn.Description = description.String
+		if trn.Currency, err = r.commodity(cmdtyID); err != nil {
+			return nil, err
+		}
+		for _, x := range []struct {
+			v  string
+			ts *types.Timespec
+		}{{posted.String, &trn.DatePosted}, {entered.String, &trn.DateEntered}} {
+			t, err := parseSQLTime(x.v)
+			if err != nil {
+				return nil, err
+			}
+			*x.ts = types.Timespec(t)
+		}
+		if trn.Slots, err = r.objSlots(trn.ID); err != nil {
+			return nil, err
+		}
+		trnMap[trn.ID] = &trn
+		list = append(list, &trn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.readSplits(trnMap, accMap, templAccMap, lotMap); err != nil {
+		return nil, err
+	}
+
+	var templates Transactions
+	for _, trn := range list {
+		if len(trn.Splits) > 0 && templAccMap[trn.Splits[0].Account.ID] != nil {
+			templates.Add(trn)
+			continue
+		}
+		book.Transactions.Add(trn)
+	}
+	return templates, nil
+}
+
+func (r *sqlReader) readSplits(trnMap TransactionMap, accMap, templAccMap AccountMap, lotMap LotMap) error {
+	rows, err := r.db.Query(`SELECT guid, tx_guid, account_guid, memo, action, reconcile_state,
+		reconcile_date, value_num, value_denom, quantity_num, quantity_denom, lot_guid
+		FROM splits`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			split                                 Split
+			trnID, accID                          types.GUID
+			memo, action, reconciled, date, lotID sql.NullString
+			valueNum, valueDen, qtyNum, qtyDen    int64
+		)
+		if err := rows.Scan(&split.ID, &trnID, &accID, &memo, &action, &reconciled,
+			&date, &valueNum, &valueDen, &qtyNum, &qtyDen, &lotID); err != nil {
+			return err
+		}
+		split.Memo = memo.String
+		split.Action = action.String
+		if split.ReconciledState, err = types.ReconciledStateFromString(reconciled.String); err != nil {
+			return err
+		}
+		t, err := parseSQLTime(date.String)
+		if err != nil {
+			return err
+		}
+		split.ReconcileDate = types.Timespec(t)
+		split.Value = *types.FromInt64(valueNum, valueDen)
+		split.Quantity = *types.FromInt64(qtyNum, qtyDen)
+		if split.Slots, err = r.objSlots(split.ID); err != nil {
+			return err
+		}
+
+		trn, ok := trnMap[trnID]
+		if !ok {
+			return fmt.Errorf("Transaction not found: %s", trnID)
+		}
+		acc, ok := accMap[accID]
+		if !ok {
+			if acc, ok = templAccMap[accID]; !ok {
+				return fmt.Errorf("Account not found: %s", accID)
+			}
+		}
+		split.Account = acc
+		if lotID.String != "" {
+			lot, ok := lotMap[types.GUID(lotID.String)]
+			if !ok {
+				return fmt.Errorf("Lot not found: %s", lotID.String)
+			}
+			split.Lot = lot
+		}
+		split.Transaction = trn
+		trn.Splits.Add(&split)
+	}
+	return rows.Err()
+}
+
+func (r *sqlReader) readPrices(book *Book) error {
+	rows, err := r.db.Query(`SELECT guid, commodity_guid, currency_guid, date, source, type,
+		value_num, value_denom FROM prices`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			p                             Price
+			cmdtyID, currID, date, source sql.NullString
+			typ                           sql.NullString
+			num, den                      int64
+		)
+		if err := rows.Scan(&p.ID, &cmdtyID, &currID, &date, &source, &typ, &num, &den); err != nil {
+			return err
+		}
+		if p.Commodity, err = r.commodity(cmdtyID); err != nil {
+			return err
+		}
+		if p.Currency, err = r.commodity(currID); err != nil {
+			return err
+		}
+		t, err := parseSQLTime(date.String)
+		if err != nil {
+			return err
+		}
+		p.Time = types.Timespec(t)
+		p.Source = source.String
+		p.Type = typ.String
+		p.Value = *types.FromInt64(num, den)
+		book.PriceDB.Add(&p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	book.PriceDB.Sort()
+	return nil
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// testSQLiteBook is a GnuCash 3 SQLite book: a bank account receiving a
+// salary in a lot, and a price of the ACME stock.
+var testSQLiteBook = []string{
+	`CREATE TABLE books (guid text(32) PRIMARY KEY NOT NULL, root_account_guid text(32) NOT NULL,
+		root_template_guid text(32) NOT NULL)`,
+	`CREATE TABLE commodities (guid text(32) PRIMARY KEY NOT NULL, namespace text(2048) NOT NULL,
+		mnemonic text(2048) NOT NULL, fullname text(2048), cusip text(2048), fraction integer NOT NULL,
+		quote_flag integer NOT NULL, quote_source text(2048), quote_tz text(2048))`,
+	`CREATE TABLE accounts (guid text(32) PRIMARY KEY NOT NULL, name text(2048) NOT NULL,
+		account_type text(2048) NOT NULL, commodity_guid text(32), commodity_scu integer NOT NULL,
+		non_std_scu integer NOT NULL, parent_guid text(32), code text(2048), description text(2048),
+		hidden integer, placeholder integer)`,
+	`CREATE TABLE transactions (guid text(32) PRIMARY KEY NOT NULL, currency_guid text(32) NOT NULL,
+		num text(2048) NOT NULL, post_date text(19), enter_date text(19), description text(2048))`,
+	`CREATE TABLE splits (guid text(32) PRIMARY KEY NOT NULL, tx_guid text(32) NOT NULL,
+		account_guid text(32) NOT NULL, memo text(2048) NOT NULL, action text(2048) NOT NULL,
+		reconcile_state text(1) NOT NULL, reconcile_date text(19), value_num bigint NOT NULL,
+		value_denom bigint NOT NULL, quantity_num bigint NOT NULL, quantity_denom bigint NOT NULL,
+		lot_guid text(32))`,
+	`CREATE TABLE prices (guid text(32) PRIMARY KEY NOT NULL, commodity_guid text(32) NOT NULL,
+		currency_guid text(32) NOT NULL, date text(19) NOT NULL, source text(2048), type text(2048),
+		value_num bigint NOT NULL, value_denom bigint NOT NULL)`,
+	`CREATE TABLE lots (guid text(32) PRIMARY KEY NOT NULL, account_guid text(32), is_closed integer NOT NULL)`,
+	`CREATE TABLE slots (id integer PRIMARY KEY AUTOINCREMENT NOT NULL, obj_guid text(32) NOT NULL,
+		name text(4096) NOT NULL, slot_type integer NOT NULL, int64_val bigint, string_val text(4096),
+		double_val float8, timespec_val text(19), guid_val text(32), numeric_val_num bigint,
+		numeric_val_denom bigint, gdate_val text(8))`,
+
+	`INSERT INTO books VALUES ('b0000000000000000000000000000001', 'a0000000000000000000000000000001',
+		'a0000000000000000000000000000009')`,
+	`INSERT INTO commodities VALUES ('c0000000000000000000000000000001', 'CURRENCY', 'EUR', 'Euro', '978',
+		100, 1, 'currency', '')`,
+	`INSERT INTO commodities VALUES ('c0000000000000000000000000000002', 'NASDAQ', 'ACME', 'Acme Corp', NULL,
+		10000, 0, NULL, NULL)`,
+	`INSERT INTO accounts VALUES ('a0000000000000000000000000000002', 'Bank', 'BANK',
+		'c0000000000000000000000000000001', 100, 0, 'a0000000000000000000000000000004', '1001', 'Current', 0, 0)`,
+	`INSERT INTO accounts VALUES ('a0000000000000000000000000000001', 'Root Account', 'ROOT',
+		NULL, 0, 0, NULL, '', '', 0, 0)`,
+	`INSERT INTO accounts VALUES ('a0000000000000000000000000000004', 'Assets', 'ASSET',
+		'c0000000000000000000000000000001', 100, 0, 'a0000000000000000000000000000001', '', '', 0, 1)`,
+	`INSERT INTO accounts VALUES ('a0000000000000000000000000000003', 'Salary', 'INCOME',
+		'c0000000000000000000000000000001', 100, 0, 'a0000000000000000000000000000001', '', '', 1, 0)`,
+	`INSERT INTO accounts VALUES ('a0000000000000000000000000000009', 'Template Root', 'ROOT',
+		NULL, 0, 0, NULL, '', '', 0, 0)`,
+	`INSERT INTO transactions VALUES ('d0000000000000000000000000000001', 'c0000000000000000000000000000001',
+		'42', '2016-01-27 11:00:00', '2016-01-28 08:30:00', 'Salary')`,
+	`INSERT INTO splits VALUES ('e0000000000000000000000000000001', 'd0000000000000000000000000000001',
+		'a0000000000000000000000000000002', 'net', '', 'c', '2016-02-01 11:00:00', 150025, 100, 150025, 100,
+		'f0000000000000000000000000000001')`,
+	`INSERT INTO splits VALUES ('e0000000000000000000000000000002', 'd0000000000000000000000000000001',
+		'a0000000000000000000000000000003', '', '', 'n', NULL, -150025, 100, -150025, 100, NULL)`,
+	`INSERT INTO prices VALUES ('90000000000000000000000000000001', 'c0000000000000000000000000000002',
+		'c0000000000000000000000000000001', '2016-01-29 10:59:00', 'user:price-editor', 'last', 3125, 100)`,
+	`INSERT INTO lots VALUES ('f0000000000000000000000000000001', 'a0000000000000000000000000000002', 0)`,
+
+	// notes and a frame on the transaction, a title on the lot
+	`INSERT INTO slots (obj_guid, name, slot_type, string_val) VALUES
+		('d0000000000000000000000000000001', 'notes', 4, 'January')`,
+	`INSERT INTO slots (obj_guid, name, slot_type, guid_val) VALUES
+		('d0000000000000000000000000000001', 'payroll', 9, '80000000000000000000000000000001')`,
+	`INSERT INTO slots (obj_guid, name, slot_type, numeric_val_num, numeric_val_denom) VALUES
+		('80000000000000000000000000000001', 'payroll/gross', 3, 200000, 100)`,
+	`INSERT INTO slots (obj_guid, name, slot_type, gdate_val) VALUES
+		('80000000000000000000000000000001', 'payroll/period', 10, '20160131')`,
+	`INSERT INTO slots (obj_guid, name, slot_type, string_val) VALUES
+		('f0000000000000000000000000000001', 'title', 4, 'January salary')`,
+}
+
+func writeTestSQLiteBook(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "test.gnucash")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range testSQLiteBook {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+	return path
+}
+
+func TestReadSQLiteFileEscapedPath(t *testing.T) {
+	src := writeTestSQLiteBook(t)
+	path := filepath.Join(filepath.Dir(src), "book?v=1#a %41.gnucash")
+	if err := os.Rename(src, path); err != nil {
+		t.Fatal(err)
+	}
+	gnc, err := ReadSQLiteFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if gnc.Book.ID != "b0000000000000000000000000000001" {
+		t.Errorf("Book.ID: got %s", gnc.Book.ID)
+	}
+}
+
+func TestReadSQLiteFile(t *testing.T) {
+	// ReadFile detects the SQLite format
+	gnc, err := ReadFile(writeTestSQLiteBook(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	book := gnc.Book
+
+	if book.ID != "b0000000000000000000000000000001" {
+		t.Errorf("Book.ID: got %s", book.ID)
+	}
+	if n := book.Commodities.Len(); n != 2 {
+		t.Errorf("Commodities: expected 2, got %d", n)
+	}
+	acme := book.Commodities.Get("NASDAQ", "ACME")
+	if acme == nil || acme.Fraction != "10000" || acme.Name != "Acme Corp" || bool(acme.GetQuotes) {
+		t.Errorf("Commodity ACME: got %+v", acme)
+	}
+
+	// parents are linked even if they come after their children
+	if n := book.Accounts.Len(); n != 4 {
+		t.Errorf("Accounts: expected 4, got %d", n)
+	}
+	if n := book.TemplateAccounts.Len(); n != 1 {
+		t.Errorf("TemplateAccounts: expected 1, got %d", n)
+	}
+	bank := book.AccountMap["a0000000000000000000000000000002"]
+	if bank == nil {
+		t.Fatal("Bank account not found")
+	}
+	if name := bank.FullName(); name != "Root Account/Assets/Bank" {
+		t.Errorf("Bank.FullName: expected %q, got %q", "Root Account/Assets/Bank", name)
+	}
+	if bank.Code != "1001" || bank.Description != "Current" || bank.Currency.ID != "EUR" || bank.CommodityScu != 100 {
+		t.Errorf("Bank: got %+v", bank)
+	}
+	if !bank.Parent.Placeholder() {
+		t.Errorf("Assets: expected placeholder")
+	}
+	if !book.AccountMap["a0000000000000000000000000000003"].Hidden() {
+		t.Errorf("Salary: expected hidden")
+	}
+
+	if n := book.Transactions.Len(); n != 1 {
+		t.Fatalf("Transactions: expected 1, got %d", n)
+	}
+	trn := book.Transactions[0]
+	if trn.Num != "42" || trn.Description != "Salary" || trn.Notes() != "January" {
+		t.Errorf("Transaction: got %+v", trn)
+	}
+	posted := time.Date(2016, 1, 27, 11, 0, 0, 0, time.UTC)
+	if !time.Time(trn.DatePosted).Equal(posted) {
+		t.Errorf("DatePosted: expected %s, got %s", posted, trn.DatePosted)
+	}
+	if v := trn.Slots.GetNumeric("payroll/gross"); v == nil || !types.Sub(v, types.New(2000, 1)).IsZero() {
+		t.Errorf("payroll/gross: got %v", v)
+	}
+	if v := trn.Slots.Get("payroll/period"); v == nil || types.GDate(time.Date(2016, 1, 31, 0, 0, 0, 0, time.UTC)) != v.GDate {
+		t.Errorf("payroll/period: got %v", v)
+	}
+
+	s := book.SplitMap["e0000000000000000000000000000001"]
+	if s == nil || s.Account != bank || s.Transaction != trn || s.Memo != "net" ||
+		s.ReconciledState != types.ReconciledStateC || s.Value.GncString() != "150025/100" {
+		t.Errorf("Split: got %+v", s)
+	}
+	lot := book.LotMap["f0000000000000000000000000000001"]
+	if lot == nil || s.Lot != lot || lot.Title() != "January salary" || lot.Splits.Len() != 1 {
+		t.Errorf("Lot: got %+v", lot)
+	}
+
+	if n := book.PriceDB.Len(); n != 1 {
+		t.Fatalf("Prices: expected 1, got %d", n)
+	}
+	p := book.PriceDB.Prices[0]
+	if p.Commodity != acme || p.Currency.ID != "EUR" || p.Value.GncString() != "3125/100" || p.Type != "last" {
+		t.Errorf("Price: got %+v", p)
+	}
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrStop can be returned by the function called by StreamTransactions
+// to stop reading the book. It is not returned by StreamTransactions.
+var ErrStop = errors.New("stop streaming")
+
+// TransactionFilter type selects the transactions of StreamTransactions.
+// The zero value selects every transaction.
+type TransactionFilter struct {
+	// From and To are the first and the last DatePosted of the
+	// transactions, both included. Zero values are not limits.
+	From, To time.Time
+	// Account, if not nil, selects the transactions with a split
+	// in an account for which it returns true.
+	Account func(acc *Account) bool
+}
+
+// Match returns true if the transaction is selected by the filter.
+func (f *TransactionFilter) Match(t *Transaction) bool {
+	posted := time.Time(t.DatePosted)
+	if !f.From.IsZero() && posted.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && posted.After(f.To) {
+		return false
+	}
+	if f.Account == nil {
+		return true
+	}
+	for _, s := range t.Splits {
+		if f.Account(s.Account) {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamTransactions reads the book in XML format from r, compressed
+// with gzip or not, calling fn for each transaction selected by the
+// filter, in file order, as soon as it is decoded.
+//
+// Only commodities, accounts and lots are kept in memory, so that the
+// splits have their accounts and lots resolved: the transactions are
+// not kept, and the lots have no splits. Prices, template transactions,
+// scheduled transactions, budgets and business objects are skipped.
+//
+// If fn returns an error, reading stops and the error is returned,
+// unless it is ErrStop.
+func StreamTransactions(r io.Reader, filter *TransactionFilter, fn func(t *Transaction) error) error {
+	reader, err := xmlReader(r)
+	if err != nil {
+		return err
+	}
+	if filter == nil {
+		filter = &TransactionFilter{}
+	}
+
+	var (
+		decoder     = xml.NewDecoder(reader)
+		commodities Commodities
+		accounts    Accounts
+		accMap      = AccountMap{}
+		lotMap      = LotMap{}
+		// accounts inserted since the last resolve
+		unresolved bool
+	)
+
+	for {
+		t, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "gnc-v2", "book":
+			// read the children
+		case "commodity":
+			var cmdty Commodity
+			if err := decoder.DecodeElement(&cmdty, &se); err != nil {
+				return err
+			}
+			commodities.Add(&cmdty)
+		case "account":
+			account, parentID, err := AccountUnmarshalXML(decoder, commodities)
+			if err != nil {
+				return err
+			}
+			if err := accounts.insert(account, parentID, accMap); err != nil {
+				return err
+			}
+			unresolved = true
+			for _, lot := range account.Lots {
+				lotMap[lot.ID] = lot
+			}
+		case "transaction":
+			trn, err := transactionUnmarshalXML(decoder, commodities, accMap, lotMap)
+			if err != nil {
+				return err
+			}
+			if unresolved {
+				accounts.setRoot(accounts.resolve(accMap))
+				unresolved = false
+			}
+			if !filter.Match(trn) {
+				continue
+			}
+			if err := fn(trn); err != nil {
+				if err == ErrStop {
+					return nil
+				}
+				return err
+			}
+		default:
+			if err := decoder.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StreamTransactionsFile calls StreamTransactions on the file,
+// that must be in XML format.
+func StreamTransactionsFile(path string, filter *TransactionFilter, fn func(t *Transaction) error) error {
+	b, err := Detect(path)
+	if err != nil {
+		return err
+	}
+	if _, ok := b.(xmlBackend); !ok {
+		return fmt.Errorf("Format %s cannot be streamed", b.Format())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return StreamTransactions(f, filter, fn)
+}
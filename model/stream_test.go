@@ -0,0 +1,84 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// streamTestBook returns the GUIDs of the transactions of the book
+// selected by the filter.
+func streamTestBook(t *testing.T, body string, filter *TransactionFilter) []types.GUID {
+	var ids []types.GUID
+	r := strings.NewReader(testXMLHeader + body + testXMLFooter)
+	err := StreamTransactions(r, filter, func(trn *Transaction) error {
+		for _, s := range trn.Splits {
+			if s.Account == nil || s.Transaction != trn {
+				t.Errorf("split %s not resolved", s.ID)
+			}
+		}
+		ids = append(ids, trn.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamTransactions: unexpected error: %s", err)
+	}
+	return ids
+}
+
+func TestStreamTransactions(t *testing.T) {
+	book := readTestBook(t, testInvoiceBook)
+	if book.Transactions.Len() < 2 {
+		t.Fatalf("expected at least 2 transactions")
+	}
+	first, last := book.Transactions[0], book.Transactions[book.Transactions.Len()-1]
+	vat := book.AccountMap["000000000000000000000000000000c3"]
+
+	var testCases = []struct {
+		name     string
+		filter   *TransactionFilter
+		expected int
+	}{
+		{"all", nil, book.Transactions.Len()},
+		{"from", &TransactionFilter{From: time.Time(last.DatePosted)}, 1},
+		{"to", &TransactionFilter{To: time.Time(first.DatePosted)}, 1},
+		{"account", &TransactionFilter{Account: func(acc *Account) bool { return acc.ID == vat.ID }}, 1},
+		{"none", &TransactionFilter{From: time.Time(last.DatePosted).AddDate(0, 0, 1)}, 0},
+	}
+
+	for _, tc := range testCases {
+		ids := streamTestBook(t, testInvoiceBook, tc.filter)
+		if len(ids) != tc.expected {
+			t.Errorf("%s: expected %d transactions, got %d", tc.name, tc.expected, len(ids))
+		}
+		for _, id := range ids {
+			if book.TransactionMap[id] == nil {
+				t.Errorf("%s: unexpected transaction %s", tc.name, id)
+			}
+		}
+	}
+}
+
+func TestStreamTransactionsStop(t *testing.T) {
+	var n int
+	r := strings.NewReader(testXMLHeader + testInvoiceBook + testXMLFooter)
+	err := StreamTransactions(r, nil, func(trn *Transaction) error {
+		n++
+		return ErrStop
+	})
+	if err != nil || n != 1 {
+		t.Errorf("ErrStop: expected 1 transaction and no error, got %d and %v", n, err)
+	}
+
+	errTest := errors.New("test")
+	r = strings.NewReader(testXMLHeader + testInvoiceBook + testXMLFooter)
+	err = StreamTransactions(r, nil, func(trn *Transaction) error {
+		return errTest
+	})
+	if err != errTest {
+		t.Errorf("expected error %v, got %v", errTest, err)
+	}
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+TaxTable = element gnc:GncTaxTable {
+  attribute version { "2.0.0" },
+  element taxtable:guid { attribute type { "guid" }, GUID }?,
+  element taxtable:name { text },
+  element taxtable:refcount { xsd:int },
+  element taxtable:invisible { xsd:boolean { pattern = "[01]" } },
+  element taxtable:child { attribute type { "guid" }, GUID }?,
+  element taxtable:parent { attribute type { "guid" }, GUID }?,
+  element taxtable:entries { TaxTableEntry* }
+}
+
+TaxTableEntry = element gnc:GncTaxTableEntry {
+  element tte:acct { attribute type { "guid" }, GUID }?,
+  element tte:amount { GncNumeric },
+  element tte:type { "VALUE" | "PERCENT" }
+}
+*/
+
+// AmountType enum type
+type AmountType string
+
+// AmountType constants
+const (
+	AmountTypeValue   AmountType = "VALUE"
+	AmountTypePercent AmountType = "PERCENT"
+)
+
+// TaxTable type
+type TaxTable struct {
+	ID        types.GUID       `xml:"guid"`
+	Name      string           `xml:"name"`
+	RefCount  int              `xml:"refcount"`
+	Invisible bool             `xml:"invisible"`
+	ChildID   types.GUID       `xml:"child"`
+	ParentID  types.GUID       `xml:"parent"`
+	Entries   []*TaxTableEntry `xml:"entries>GncTaxTableEntry"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
+
+// TaxTableEntry type
+type TaxTableEntry struct {
+	AccountID types.GUID    `xml:"acct"`
+	Amount    types.Numeric `xml:"amount"`
+	Type      AmountType    `xml:"type"`
+
+	// Account is the account the tax is posted to.
+	Account *Account `xml:"-"`
+}
+
+// rates returns the sum of the fixed values and of the percent rates
+// (as a fraction, not multiplied by 100) of the tax table.
+func (tt *TaxTable) rates() (value, percent *types.Numeric) {
+	value, percent = &types.Numeric{}, &types.Numeric{}
+	if tt == nil {
+		return
+	}
+	for _, tte := range tt.Entries {
+		switch tte.Type {
+		case AmountTypeValue:
+			value.AddEqual(&tte.Amount)
+		case AmountTypePercent:
+			percent.AddEqual(types.Div(&tte.Amount, hundred))
+		}
+	}
+	return
+}
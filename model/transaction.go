@@ -32,14 +32,20 @@ type Transactions []*Transaction
 
 // Transaction type
 type Transaction struct {
+	ID          types.GUID     `xml:"id"`
 	Currency    *Commodity     `xml:"currency"`
+	Num         string         `xml:"num"`
 	DatePosted  types.Timespec `xml:"date-posted>date"`
 	DateEntered types.Timespec `xml:"date-entered>date"`
 	Description string         `xml:"description"`
+	Slots       Slots          `xml:"slots"`
 	Splits      Splits         `xml:"splits>split"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
 }
 
-func transactionUnmarshalXML(decoder *xml.Decoder, commodities Commodities, accMap AccountMap) (*Transaction, error) {
+func transactionUnmarshalXML(decoder *xml.Decoder, commodities Commodities, accMap AccountMap, lotMap LotMap) (*Transaction, error) {
 
 	var trn Transaction
 	var cmdty Commodity
@@ -47,9 +53,9 @@ func transactionUnmarshalXML(decoder *xml.Decoder, commodities Commodities, accM
 LOOP:
 	for {
 		// Read tokens from the XML document in a stream.
-		token, _ := decoder.Token()
-		if token == nil {
-			break LOOP
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
 		}
 		// Inspect the type of the token just read.
 		switch se := token.(type) {
@@ -57,28 +63,40 @@ LOOP:
 			var v interface{}
 
 			switch se.Name.Local {
+			case "id":
+				v = &trn.ID
 			case "currency":
 				v = &cmdty
+			case "num":
+				v = &trn.Num
 			case "description":
 				v = &trn.Description
+			case "slots":
+				v = &trn.Slots
 			case "date-posted":
 				v = &trn.DatePosted
 			case "date-entered":
 				v = &trn.DateEntered
 			case "splits":
-				if err := SplitsUnmarshalXML(decoder, &trn.Splits, accMap); err != nil {
-					return nil, err
+				if err := SplitsUnmarshalXML(decoder, &trn.Splits, accMap, lotMap); err != nil {
+					return nil, parseError(decoder, se.Name, err)
+				}
+				for _, s := range trn.Splits {
+					s.Transaction = &trn
+				}
+			default:
+				if err := trn.addElement(decoder, &se); err != nil {
+					return nil, parseError(decoder, se.Name, err)
 				}
-
 			}
 
 			if v != nil {
 				if err := decoder.DecodeElement(v, &se); err != nil {
-					return nil, err
+					return nil, parseError(decoder, se.Name, err)
 				}
 				switch se.Name.Local {
 				case "currency":
-					trn.Currency = commodities.Get(cmdty.Space, cmdty.ID)
+					trn.Currency = commodities.Lookup(&cmdty)
 				}
 			}
 
@@ -92,6 +110,20 @@ LOOP:
 	return &trn, nil
 }
 
+// Notes returns the notes of the transaction.
+func (t *Transaction) Notes() string {
+	return t.Slots.GetString("notes")
+}
+
+// VoidReason returns the reason the transaction was voided,
+// or "" if the transaction is not void.
+func (t *Transaction) VoidReason() string {
+	return t.Slots.GetString("void-reason")
+}
+
+// TransactionMap maps the GUID of a transaction to the transaction.
+type TransactionMap map[types.GUID]*Transaction
+
 // Add adds a transaction to the collection
 func (ts *Transactions) Add(t *Transaction) {
 	*ts = append(*ts, t)
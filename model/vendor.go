@@ -0,0 +1,52 @@
+package model
+
+import (
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+/*
+Vendor = element gnc:GncVendor {
+  attribute version { "2.0.0" },
+  element vendor:guid { attribute type { "guid" }, GUID },
+  element vendor:name { text },
+  element vendor:id { text },
+  element vendor:addr { AddressContent },
+  element vendor:notes { text }?,
+  element vendor:terms { attribute type { "guid" }, GUID }?,
+  element vendor:taxincluded { "YES" | "NO" | "USEGLOBAL"},
+  element vendor:active { xsd:boolean { pattern = "[01]" } },
+  element vendor:currency {
+    element cmdty:space { text },
+    element cmdty:id { text }
+  },
+  element vendor:use-tt { xsd:boolean { pattern = "[01]" } },
+  element vendor:taxtable { attribute type { "guid" }, GUID }?,
+  element vendor:slots { KvpSlot+ }?
+}
+*/
+
+// Vendor type
+type Vendor struct {
+	ID      types.GUID `xml:"guid"`
+	Name    string     `xml:"name"`
+	Number  string     `xml:"id"`
+	Addr    Address    `xml:"addr"`
+	Notes   string     `xml:"notes"`
+	TermsID types.GUID `xml:"terms"`
+	// TaxIncluded is one of "YES", "NO", "USEGLOBAL".
+	TaxIncluded string     `xml:"taxincluded"`
+	Active      bool       `xml:"active"`
+	Currency    *Commodity `xml:"currency"`
+	UseTaxTable bool       `xml:"use-tt"`
+	TaxTableID  types.GUID `xml:"taxtable"`
+	Slots       Slots      `xml:"slots"`
+
+	// Terms and TaxTable are the resolved TermsID and TaxTableID.
+	Terms    *BillTerm `xml:"-"`
+	TaxTable *TaxTable `xml:"-"`
+	// Jobs owned by the vendor.
+	Jobs []*Job `xml:"-"`
+
+	// elements and attributes not understood by the decoder
+	Unknown
+}
@@ -0,0 +1,906 @@
+package model
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// namespaces are the XML namespaces declared by the gnc-v2 element,
+// in the order written by GnuCash.
+var namespaces = []struct{ prefix, uri string }{
+	{"gnc", "http://www.gnucash.org/XML/gnc"},
+	{"act", "http://www.gnucash.org/XML/act"},
+	{"book", "http://www.gnucash.org/XML/book"},
+	{"cd", "http://www.gnucash.org/XML/cd"},
+	{"cmdty", "http://www.gnucash.org/XML/cmdty"},
+	{"price", "http://www.gnucash.org/XML/price"},
+	{"slot", "http://www.gnucash.org/XML/slot"},
+	{"split", "http://www.gnucash.org/XML/split"},
+	{"sx", "http://www.gnucash.org/XML/sx"},
+	{"trn", "http://www.gnucash.org/XML/trn"},
+	{"ts", "http://www.gnucash.org/XML/ts"},
+	{"fs", "http://www.gnucash.org/XML/fs"},
+	{"bgt", "http://www.gnucash.org/XML/bgt"},
+	{"recurrence", "http://www.gnucash.org/XML/recurrence"},
+	{"lot", "http://www.gnucash.org/XML/lot"},
+	{"addr", "http://www.gnucash.org/XML/addr"},
+	{"owner", "http://www.gnucash.org/XML/owner"},
+	{"billterm", "http://www.gnucash.org/XML/billterm"},
+	{"bt-days", "http://www.gnucash.org/XML/bt-days"},
+	{"bt-prox", "http://www.gnucash.org/XML/bt-prox"},
+	{"cust", "http://www.gnucash.org/XML/cust"},
+	{"employee", "http://www.gnucash.org/XML/employee"},
+	{"entry", "http://www.gnucash.org/XML/entry"},
+	{"invoice", "http://www.gnucash.org/XML/invoice"},
+	{"job", "http://www.gnucash.org/XML/job"},
+	{"order", "http://www.gnucash.org/XML/order"},
+	{"taxtable", "http://www.gnucash.org/XML/taxtable"},
+	{"tte", "http://www.gnucash.org/XML/tte"},
+	{"vendor", "http://www.gnucash.org/XML/vendor"},
+}
+
+// WriteOptions type
+type WriteOptions struct {
+	// Plain writes uncompressed XML.
+	// By default the file is gzip compressed, as GnuCash does.
+	Plain bool
+}
+
+// WriteFile writes the gnucash file in XML format.
+// A nil opts uses the default options.
+func WriteFile(path string, gnc *Gnc, opts *WriteOptions) error {
+	if opts == nil {
+		opts = &WriteOptions{}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = f
+	var zw *gzip.Writer
+	if !opts.Plain {
+		zw = gzip.NewWriter(f)
+		w = zw
+	}
+
+	err = Write(w, gnc)
+	if zw != nil {
+		if e := zw.Close(); err == nil {
+			err = e
+		}
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Write writes the gnucash book as gnc-v2 XML, following the element order
+// of doc/gnucash-v2.rnc. The chart of an account template is written with
+// its gnc-account-example root, title and descriptions.
+func Write(w io.Writer, gnc *Gnc) error {
+	x := &xmlWriter{w: bufio.NewWriter(w)}
+
+	root := "gnc-v2"
+	template := gnc.Book == nil && gnc.Chart != nil && isChartRoot(gnc.XMLName)
+	if template {
+		root = gnc.XMLName.Local
+	}
+
+	x.w.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\" ?>\n<" + root)
+	for _, ns := range namespaces {
+		x.w.WriteString("\n     xmlns:" + ns.prefix + "=\"" + ns.uri + "\"")
+	}
+	// namespaces of the elements not understood by the decoder
+	x.local = nsDecls(gnc.Attrs, map[string]string{})
+	if _, ok := x.local[nsGncAct]; template && !ok {
+		x.w.WriteString("\n     xmlns:gnc-act=\"" + nsGncAct + "\"")
+		x.local[nsGncAct] = "gnc-act"
+	}
+	for _, a := range gnc.Attrs {
+		if a.Name.Space == "xmlns" && prefixes[a.Value] == a.Name.Local {
+			continue
+		}
+		x.w.WriteString("\n     " + qualifiedName(a.Name, x.local) + "=\"")
+		xml.EscapeText(x.w, []byte(a.Value))
+		x.w.WriteString("\"")
+	}
+	x.w.WriteString(">\n")
+	x.depth++
+
+	switch {
+	case gnc.Book != nil:
+		x.count("book", 1)
+		x.book(gnc.Book)
+	case template:
+		x.template(gnc.Chart)
+	case gnc.Chart != nil:
+		x.chart(gnc.Chart)
+	}
+
+	x.depth--
+	x.w.WriteString("</" + root + ">\n")
+
+	return x.w.Flush()
+}
+
+// xmlWriter writes the XML elements one per line, indented as GnuCash does:
+// the children of gnc-v2 and of gnc:book are not indented.
+// Write errors are kept by the bufio.Writer and returned by Flush.
+type xmlWriter struct {
+	w     *bufio.Writer
+	depth int
+	// local maps the URIs of the namespaces not known to the writer
+	// to the prefixes declared in the input.
+	local map[string]string
+}
+
+func (x *xmlWriter) indent() {
+	for j := 2; j < x.depth; j++ {
+		x.w.WriteString("  ")
+	}
+}
+
+func (x *xmlWriter) tag(name string, attrs []string) {
+	x.w.WriteString("<" + name)
+	for j := 0; j+1 < len(attrs); j += 2 {
+		x.w.WriteString(" " + attrs[j] + "=\"")
+		xml.EscapeText(x.w, []byte(attrs[j+1]))
+		x.w.WriteString("\"")
+	}
+}
+
+// start writes the start tag of an element with children.
+// attrs are pairs of attribute name and value.
+func (x *xmlWriter) start(name string, attrs ...string) {
+	x.indent()
+	x.tag(name, attrs)
+	x.w.WriteString(">\n")
+	x.depth++
+}
+
+// end writes the end tag of an element with children.
+func (x *xmlWriter) end(name string) {
+	x.depth--
+	x.indent()
+	x.w.WriteString("</" + name + ">\n")
+}
+
+// objStart writes the start tag of a book object,
+// followed by the attributes not understood by the decoder.
+func (x *xmlWriter) objStart(name string, u *Unknown, attrs ...string) {
+	x.local = nsDecls(u.UnknownAttrs, x.local)
+	for _, a := range u.attrs() {
+		attrs = append(attrs, qualifiedName(a.Name, x.local), a.Value)
+	}
+	x.start(name, attrs...)
+}
+
+// objEnd writes the elements of a book object not understood by the decoder,
+// followed by the end tag.
+func (x *xmlWriter) objEnd(name string, u *Unknown) {
+	for _, e := range u.UnknownElements {
+		x.raw(e)
+	}
+	x.end(name)
+}
+
+// raw writes an element not understood by the decoder as it was read.
+func (x *xmlWriter) raw(e *RawElement) {
+	local := map[string]string{}
+	for uri, p := range x.local {
+		local[uri] = p
+	}
+	local = nsDecls(e.Attrs, local)
+	var attrs []string
+	name := qualifiedName(e.XMLName, local)
+	if strings.HasPrefix(name, "{") {
+		// default namespace: declared by the element itself
+		name = e.XMLName.Local
+		if !hasDefaultNS(e.Attrs) {
+			attrs = append(attrs, "xmlns", e.XMLName.Space)
+		}
+	}
+	for _, a := range e.Attrs {
+		attrs = append(attrs, qualifiedName(a.Name, local), a.Value)
+	}
+	x.indent()
+	x.tag(name, attrs)
+	if e.Inner == "" {
+		x.w.WriteString("/>\n")
+		return
+	}
+	x.w.WriteString(">" + e.Inner + "</" + name + ">\n")
+}
+
+// hasDefaultNS returns true if the attributes declare the default namespace.
+func hasDefaultNS(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == "xmlns" {
+			return true
+		}
+	}
+	return false
+}
+
+// empty writes an element without content.
+func (x *xmlWriter) empty(name string, attrs ...string) {
+	x.indent()
+	x.tag(name, attrs)
+	x.w.WriteString("/>\n")
+}
+
+// text writes an element with text content.
+func (x *xmlWriter) text(name, value string, attrs ...string) {
+	x.indent()
+	x.tag(name, attrs)
+	x.w.WriteString(">")
+	xml.EscapeText(x.w, []byte(value))
+	x.w.WriteString("</" + name + ">\n")
+}
+
+// optText writes an element with text content, if not empty.
+func (x *xmlWriter) optText(name, value string) {
+	if value != "" {
+		x.text(name, value)
+	}
+}
+
+func (x *xmlWriter) integer(name string, value int64) {
+	x.text(name, strconv.FormatInt(value, 10))
+}
+
+// boolean writes a boolean as "1" or "0".
+func (x *xmlWriter) boolean(name string, value bool) {
+	if value {
+		x.text(name, "1")
+	} else {
+		x.text(name, "0")
+	}
+}
+
+// yesNo writes a boolean as "y" or "n".
+func (x *xmlWriter) yesNo(name string, value bool) {
+	if value {
+		x.text(name, "y")
+	} else {
+		x.text(name, "n")
+	}
+}
+
+func (x *xmlWriter) numeric(name string, n *types.Numeric) {
+	x.text(name, n.GncString())
+}
+
+// optNumeric writes a numeric, if not zero.
+func (x *xmlWriter) optNumeric(name string, n *types.Numeric) {
+	if !n.IsZero() {
+		x.numeric(name, n)
+	}
+}
+
+func (x *xmlWriter) guid(name string, id types.GUID) {
+	x.text(name, string(id), "type", "guid")
+}
+
+// optGUID writes a guid, if not empty.
+func (x *xmlWriter) optGUID(name string, id types.GUID) {
+	if id != "" {
+		x.guid(name, id)
+	}
+}
+
+func (x *xmlWriter) timespec(name string, ts types.Timespec) {
+	x.start(name)
+	x.text("ts:date", ts.GncString())
+	x.end(name)
+}
+
+// optTimespec writes a timespec, if not zero.
+func (x *xmlWriter) optTimespec(name string, ts types.Timespec) {
+	if !time.Time(ts).IsZero() {
+		x.timespec(name, ts)
+	}
+}
+
+func (x *xmlWriter) gdate(name string, d types.GDate) {
+	x.start(name)
+	x.text("gdate", d.String())
+	x.end(name)
+}
+
+// optGDate writes a gdate, if not zero.
+func (x *xmlWriter) optGDate(name string, d types.GDate) {
+	if d.String() != "" {
+		x.gdate(name, d)
+	}
+}
+
+// commodityRef writes the space and id of a commodity, if not nil.
+func (x *xmlWriter) commodityRef(name string, c *Commodity) {
+	if c == nil {
+		return
+	}
+	x.start(name)
+	x.text("cmdty:space", c.Space)
+	x.text("cmdty:id", c.ID)
+	x.end(name)
+}
+
+func (x *xmlWriter) count(typ string, n int) {
+	if n > 0 {
+		x.text("gnc:count-data", strconv.Itoa(n), "cd:type", typ)
+	}
+}
+
+// slots writes the slots, if any.
+func (x *xmlWriter) slots(name string, ss Slots) {
+	if len(ss) == 0 {
+		return
+	}
+	x.start(name)
+	x.slotList(ss)
+	x.end(name)
+}
+
+func (x *xmlWriter) slotList(ss Slots) {
+	for _, s := range ss {
+		x.start("slot")
+		x.text("slot:key", s.Key)
+		x.kvpValue(s.Value)
+		x.end("slot")
+	}
+}
+
+func (x *xmlWriter) kvpValue(v *KvpValue) {
+	const name = "slot:value"
+	typ := v.Type.String()
+
+	switch v.Type {
+	case KvpTypeInteger:
+		x.text(name, strconv.FormatInt(v.Integer, 10), "type", typ)
+	case KvpTypeDouble:
+		x.text(name, strconv.FormatFloat(v.Double, 'g', -1, 64), "type", typ)
+	case KvpTypeNumeric:
+		x.text(name, v.Numeric.GncString(), "type", typ)
+	case KvpTypeString:
+		x.text(name, v.Text, "type", typ)
+	case KvpTypeGUID:
+		x.text(name, string(v.GUID), "type", typ)
+	case KvpTypeTimespec:
+		x.start(name, "type", typ)
+		x.text("ts:date", v.Timespec.GncString())
+		x.end(name)
+	case KvpTypeGDate:
+		x.start(name, "type", typ)
+		x.text("gdate", v.GDate.String())
+		x.end(name)
+	case KvpTypeBinary:
+		x.text(name, hex.EncodeToString(v.Binary), "type", typ)
+	case KvpTypeList:
+		if len(v.List) == 0 {
+			x.empty(name, "type", typ)
+			return
+		}
+		x.start(name, "type", typ)
+		for _, item := range v.List {
+			x.kvpValue(item)
+		}
+		x.end(name)
+	case KvpTypeFrame:
+		if len(v.Frame) == 0 {
+			x.empty(name, "type", typ)
+			return
+		}
+		x.start(name, "type", typ)
+		x.slotList(v.Frame)
+		x.end(name)
+	}
+}
+
+func (x *xmlWriter) recurrence(name string, r *types.Recurrence) {
+	x.start(name, "version", "1.0.0")
+	x.integer("recurrence:mult", int64(r.Mult))
+	x.text("recurrence:period_type", r.PeriodType.String())
+	x.gdate("recurrence:start", r.Start)
+	if r.WeekendAdjust != types.WeekendAdjustNone {
+		x.text("recurrence:weekend_adj", r.WeekendAdjust.String())
+	}
+	x.end(name)
+}
+
+func (x *xmlWriter) book(b *Book) {
+	x.objStart("gnc:book", &b.Unknown, "version", "2.0.0")
+	x.guid("book:id", b.ID)
+	x.slots("book:slots", b.Slots)
+
+	biz := &b.Business
+	x.count("commodity", b.Commodities.Len())
+	x.count("account", b.Accounts.Len())
+	x.count("transaction", b.Transactions.Len())
+	x.count("schedxaction", b.ScheduledTransactions.Len())
+	x.count("budget", b.Budgets.Len())
+	x.count("gnc:GncBillTerm", len(biz.BillTerms))
+	x.count("gnc:GncCustomer", len(biz.Customers))
+	x.count("gnc:GncEmployee", len(biz.Employees))
+	x.count("gnc:GncEntry", len(biz.Entries))
+	x.count("gnc:GncInvoice", len(biz.Invoices))
+	x.count("gnc:GncJob", len(biz.Jobs))
+	x.count("gnc:GncOrder", len(biz.Orders))
+	x.count("gnc:GncTaxTable", len(biz.TaxTables))
+	x.count("gnc:GncVendor", len(biz.Vendors))
+
+	for _, c := range b.Commodities {
+		x.commodity(c)
+	}
+	if b.PriceDB.Len() > 0 {
+		x.start("gnc:pricedb", "version", "1")
+		for _, p := range b.PriceDB.Prices {
+			x.price(p)
+		}
+		x.end("gnc:pricedb")
+	}
+	for _, acc := range b.Accounts.List {
+		x.account(acc)
+	}
+	for _, t := range b.Transactions {
+		x.transaction(t)
+	}
+	if b.TemplateAccounts.Len() > 0 || b.TemplateTransactions.Len() > 0 {
+		x.start("gnc:template-transactions")
+		for _, acc := range b.TemplateAccounts.List {
+			x.account(acc)
+		}
+		for _, t := range b.TemplateTransactions {
+			x.transaction(t)
+		}
+		x.end("gnc:template-transactions")
+	}
+	for _, sx := range b.ScheduledTransactions {
+		x.scheduledTransaction(sx)
+	}
+	for _, bgt := range b.Budgets {
+		x.budget(bgt)
+	}
+
+	for _, bt := range biz.BillTerms {
+		x.billTerm(bt)
+	}
+	for _, c := range biz.Customers {
+		x.customer(c)
+	}
+	for _, e := range biz.Employees {
+		x.employee(e)
+	}
+	for _, e := range biz.Entries {
+		x.entry(e)
+	}
+	for _, inv := range biz.Invoices {
+		x.invoice(inv)
+	}
+	for _, j := range biz.Jobs {
+		x.job(j)
+	}
+	for _, o := range biz.Orders {
+		x.order(o)
+	}
+	for _, tt := range biz.TaxTables {
+		x.taxTable(tt)
+	}
+	for _, v := range biz.Vendors {
+		x.vendor(v)
+	}
+
+	x.objEnd("gnc:book", &b.Unknown)
+}
+
+// chart writes the children of gnc-v2 of an accounts-only file.
+func (x *xmlWriter) chart(c *Chart) {
+	x.count("commodity", c.Commodities.Len())
+	x.count("account", c.Accounts.Len())
+	x.chartObjects(c)
+}
+
+// template writes the chart of an account template: the title and the
+// descriptions, without the counters.
+func (x *xmlWriter) template(c *Chart) {
+	p := x.local[nsGncAct]
+	x.optText(p+":title", c.Title)
+	x.optText(p+":short-description", c.ShortDescription)
+	x.optText(p+":long-description", c.LongDescription)
+	x.chartObjects(c)
+}
+
+func (x *xmlWriter) chartObjects(c *Chart) {
+	for _, cmdty := range c.Commodities {
+		x.commodity(cmdty)
+	}
+	for _, acc := range c.Accounts.List {
+		x.account(acc)
+	}
+	for _, e := range c.UnknownElements {
+		x.raw(e)
+	}
+}
+
+func (x *xmlWriter) commodity(c *Commodity) {
+	x.objStart("gnc:commodity", &c.Unknown, "version", "2.0.0")
+	x.text("cmdty:space", c.Space)
+	x.text("cmdty:id", c.ID)
+	if c.Space != "ISO4217" {
+		x.optText("cmdty:name", c.Name)
+		x.optText("cmdty:xcode", c.Xcode)
+		x.optText("cmdty:fraction", c.Fraction)
+	}
+	if c.GetQuotes {
+		x.empty("cmdty:get_quotes")
+		x.optText("cmdty:quote_source", c.QuoteSource)
+	}
+	if c.QuoteTz != nil {
+		x.text("cmdty:quote_tz", *c.QuoteTz)
+	}
+	x.slots("cmdty:slots", c.Slots)
+	x.objEnd("gnc:commodity", &c.Unknown)
+}
+
+func (x *xmlWriter) price(p *Price) {
+	x.objStart("price", &p.Unknown)
+	x.guid("price:id", p.ID)
+	x.commodityRef("price:commodity", p.Commodity)
+	x.commodityRef("price:currency", p.Currency)
+	x.timespec("price:time", p.Time)
+	x.optText("price:source", p.Source)
+	x.optText("price:type", p.Type)
+	x.numeric("price:value", &p.Value)
+	x.objEnd("price", &p.Unknown)
+}
+
+func (x *xmlWriter) account(acc *Account) {
+	x.objStart("gnc:account", &acc.Unknown, "version", "2.0.0")
+	x.text("act:name", acc.Name)
+	x.guid("act:id", acc.ID)
+	x.text("act:type", acc.Type.GncString())
+	if acc.Currency != nil {
+		x.commodityRef("act:commodity", acc.Currency)
+		x.integer("act:commodity-scu", int64(acc.CommodityScu))
+		if acc.NonStandardScu {
+			x.empty("act:non-standard-scu")
+		}
+	}
+	x.optText("act:code", acc.Code)
+	x.optText("act:description", acc.Description)
+	x.slots("act:slots", acc.Slots)
+	if acc.Parent != nil {
+		x.guid("act:parent", acc.Parent.ID)
+	} else if acc.ParentID != "" {
+		// orphan account
+		x.guid("act:parent", acc.ParentID)
+	}
+	if acc.Lots.Len() > 0 {
+		x.start("act:lots")
+		for _, lot := range acc.Lots {
+			x.objStart("gnc:lot", &lot.Unknown, "version", "2.0.0")
+			x.guid("lot:id", lot.ID)
+			x.slots("lot:slots", lot.Slots)
+			x.objEnd("gnc:lot", &lot.Unknown)
+		}
+		x.end("act:lots")
+	}
+	x.objEnd("gnc:account", &acc.Unknown)
+}
+
+func (x *xmlWriter) transaction(t *Transaction) {
+	x.objStart("gnc:transaction", &t.Unknown, "version", "2.0.0")
+	x.guid("trn:id", t.ID)
+	x.commodityRef("trn:currency", t.Currency)
+	x.optText("trn:num", t.Num)
+	x.timespec("trn:date-posted", t.DatePosted)
+	x.timespec("trn:date-entered", t.DateEntered)
+	x.optText("trn:description", t.Description)
+	x.slots("trn:slots", t.Slots)
+	x.start("trn:splits")
+	for _, s := range t.Splits {
+		x.split(s)
+	}
+	x.end("trn:splits")
+	x.objEnd("gnc:transaction", &t.Unknown)
+}
+
+func (x *xmlWriter) split(s *Split) {
+	x.objStart("trn:split", &s.Unknown)
+	x.guid("split:id", s.ID)
+	x.optText("split:memo", s.Memo)
+	x.optText("split:action", s.Action)
+	x.text("split:reconciled-state", s.ReconciledState.String())
+	x.optTimespec("split:reconcile-date", s.ReconcileDate)
+	x.numeric("split:value", &s.Value)
+	x.numeric("split:quantity", &s.Quantity)
+	if s.Account != nil {
+		x.guid("split:account", s.Account.ID)
+	}
+	if s.Lot != nil {
+		x.guid("split:lot", s.Lot.ID)
+	}
+	x.slots("split:slots", s.Slots)
+	x.objEnd("trn:split", &s.Unknown)
+}
+
+func (x *xmlWriter) scheduledTransaction(sx *ScheduledTransaction) {
+	x.objStart("gnc:schedxaction", &sx.Unknown, "version", "2.0.0")
+	x.guid("sx:id", sx.ID)
+	x.text("sx:name", sx.Name)
+	x.yesNo("sx:enabled", sx.Enabled)
+	x.yesNo("sx:autoCreate", sx.AutoCreate)
+	x.yesNo("sx:autoCreateNotify", sx.AutoCreateNotify)
+	x.integer("sx:advanceCreateDays", int64(sx.AdvanceCreateDays))
+	x.integer("sx:advanceRemindDays", int64(sx.AdvanceRemindDays))
+	x.integer("sx:instanceCount", int64(sx.InstanceCount))
+	x.gdate("sx:start", sx.Start)
+	x.optGDate("sx:last", sx.Last)
+	if sx.NumOccur > 0 {
+		x.integer("sx:num-occur", int64(sx.NumOccur))
+		x.integer("sx:rem-occur", int64(sx.RemOccur))
+	} else {
+		x.optGDate("sx:end", sx.End)
+	}
+	if sx.TemplateAccount != nil {
+		x.guid("sx:templ-acct", sx.TemplateAccount.ID)
+	} else if sx.TemplateAccountID != "" {
+		x.guid("sx:templ-acct", sx.TemplateAccountID)
+	}
+	x.start("sx:schedule")
+	for _, r := range sx.Schedule {
+		x.recurrence("gnc:recurrence", r)
+	}
+	x.end("sx:schedule")
+	for _, di := range sx.DeferredInstances {
+		x.start("sx:deferredInstance")
+		x.optGDate("sx:last", di.Last)
+		x.integer("sx:rem-occur", int64(di.RemOccur))
+		x.integer("sx:instanceCount", int64(di.InstanceCount))
+		x.end("sx:deferredInstance")
+	}
+	x.slots("sx:slots", sx.Slots)
+	x.objEnd("gnc:schedxaction", &sx.Unknown)
+}
+
+func (x *xmlWriter) budget(bgt *Budget) {
+	x.objStart("gnc:budget", &bgt.Unknown, "version", "2.0.0")
+	x.guid("bgt:id", bgt.ID)
+	x.text("bgt:name", bgt.Name)
+	x.text("bgt:description", bgt.Description)
+	x.integer("bgt:num-periods", int64(bgt.NumPeriods))
+	x.recurrence("bgt:recurrence", &bgt.Recurrence)
+	x.slots("bgt:slots", bgt.Slots)
+	x.objEnd("gnc:budget", &bgt.Unknown)
+}
+
+func (x *xmlWriter) address(name string, a *Address) {
+	if *a == (Address{}) {
+		x.empty(name, "version", "2.0.0")
+		return
+	}
+	x.start(name, "version", "2.0.0")
+	x.optText("addr:name", a.Name)
+	x.optText("addr:addr1", a.Addr1)
+	x.optText("addr:addr2", a.Addr2)
+	x.optText("addr:addr3", a.Addr3)
+	x.optText("addr:addr4", a.Addr4)
+	x.optText("addr:phone", a.Phone)
+	x.optText("addr:fax", a.Fax)
+	x.optText("addr:email", a.Email)
+	x.end(name)
+}
+
+func (x *xmlWriter) owner(name string, o *Owner) {
+	x.start(name, "version", "2.0.0")
+	x.text("owner:type", string(o.Type))
+	x.guid("owner:id", o.ID)
+	x.end(name)
+}
+
+// optOwner writes an owner, if set.
+func (x *xmlWriter) optOwner(name string, o *Owner) {
+	if o != nil && o.Type != "" {
+		x.owner(name, o)
+	}
+}
+
+func (x *xmlWriter) billTerm(bt *BillTerm) {
+	x.objStart("gnc:GncBillTerm", &bt.Unknown, "version", "2.0.0")
+	x.optGUID("billterm:guid", bt.ID)
+	x.text("billterm:name", bt.Name)
+	x.text("billterm:desc", bt.Description)
+	x.integer("billterm:refcount", int64(bt.RefCount))
+	x.boolean("billterm:invisible", bt.Invisible)
+	x.slots("billterm:slots", bt.Slots)
+	x.optGUID("billterm:child", bt.ChildID)
+	x.optGUID("billterm:parent", bt.ParentID)
+	switch {
+	case bt.Days != nil:
+		x.start("billterm:days")
+		x.integer("bt-days:due-days", int64(bt.Days.DueDays))
+		x.integer("bt-days:disc-days", int64(bt.Days.DiscDays))
+		x.numeric("bt-days:discount", &bt.Days.Discount)
+		x.end("billterm:days")
+	case bt.Proximo != nil:
+		x.start("billterm:proximo")
+		x.integer("bt-prox:due-day", int64(bt.Proximo.DueDay))
+		x.integer("bt-prox:disc-day", int64(bt.Proximo.DiscDay))
+		x.numeric("bt-prox:discount", &bt.Proximo.Discount)
+		x.integer("bt-prox:cutoff-day", int64(bt.Proximo.CutoffDay))
+		x.end("billterm:proximo")
+	}
+	x.objEnd("gnc:GncBillTerm", &bt.Unknown)
+}
+
+func (x *xmlWriter) customer(c *Customer) {
+	x.objStart("gnc:GncCustomer", &c.Unknown, "version", "2.0.0")
+	x.guid("cust:guid", c.ID)
+	x.text("cust:name", c.Name)
+	x.text("cust:id", c.Number)
+	x.address("cust:addr", &c.Addr)
+	x.address("cust:shipaddr", &c.ShipAddr)
+	x.optText("cust:notes", c.Notes)
+	x.optGUID("cust:terms", c.TermsID)
+	x.text("cust:taxincluded", c.TaxIncluded)
+	x.boolean("cust:active", c.Active)
+	x.numeric("cust:discount", &c.Discount)
+	x.numeric("cust:credit", &c.Credit)
+	x.commodityRef("cust:currency", c.Currency)
+	x.boolean("cust:use-tt", c.UseTaxTable)
+	x.optGUID("cust:taxtable", c.TaxTableID)
+	x.slots("cust:slots", c.Slots)
+	x.objEnd("gnc:GncCustomer", &c.Unknown)
+}
+
+func (x *xmlWriter) employee(e *Employee) {
+	x.objStart("gnc:GncEmployee", &e.Unknown, "version", "2.0.0")
+	x.guid("employee:guid", e.ID)
+	x.text("employee:username", e.Username)
+	x.text("employee:id", e.Number)
+	x.address("employee:addr", &e.Addr)
+	x.optText("employee:language", e.Language)
+	x.optText("employee:acl", e.Acl)
+	x.boolean("employee:active", e.Active)
+	x.numeric("employee:workday", &e.Workday)
+	x.numeric("employee:rate", &e.Rate)
+	x.commodityRef("employee:currency", e.Currency)
+	x.optGUID("employee:ccard", e.CCardID)
+	x.slots("employee:slots", e.Slots)
+	x.objEnd("gnc:GncEmployee", &e.Unknown)
+}
+
+func (x *xmlWriter) entry(e *Entry) {
+	x.objStart("gnc:GncEntry", &e.Unknown, "version", "2.0.0")
+	x.guid("entry:guid", e.ID)
+	x.timespec("entry:date", e.Date)
+	x.timespec("entry:entered", e.Entered)
+	x.optText("entry:description", e.Description)
+	x.optText("entry:action", e.Action)
+	x.optText("entry:notes", e.Notes)
+	x.optNumeric("entry:qty", &e.Quantity)
+
+	x.optGUID("entry:i-acct", e.InvAccountID)
+	x.optNumeric("entry:i-price", &e.InvPrice)
+	x.optNumeric("entry:i-discount", &e.InvDiscount)
+	if e.InvoiceID != "" {
+		x.guid("entry:invoice", e.InvoiceID)
+		x.optText("entry:i-disc-type", string(e.InvDiscountType))
+		x.optText("entry:i-disc-how", string(e.InvDiscountHow))
+		x.boolean("entry:i-taxable", e.InvTaxable)
+		x.boolean("entry:i-taxincluded", e.InvTaxIncluded)
+	}
+	x.optGUID("entry:i-taxtable", e.InvTaxTableID)
+
+	x.optGUID("entry:b-acct", e.BillAccountID)
+	x.optNumeric("entry:b-price", &e.BillPrice)
+	if e.BillID != "" {
+		x.guid("entry:bill", e.BillID)
+		x.boolean("entry:billable", e.Billable)
+		x.optOwner("entry:billto", e.BillTo)
+		x.boolean("entry:b-taxable", e.BillTaxable)
+		x.boolean("entry:b-taxincluded", e.BillTaxIncluded)
+		x.optText("entry:b-pay", e.BillPayment)
+	}
+	x.optGUID("entry:b-taxtable", e.BillTaxTableID)
+	x.optGUID("entry:order", e.OrderID)
+	x.objEnd("gnc:GncEntry", &e.Unknown)
+}
+
+func (x *xmlWriter) invoice(inv *Invoice) {
+	x.objStart("gnc:GncInvoice", &inv.Unknown, "version", "2.0.0")
+	x.guid("invoice:guid", inv.ID)
+	x.text("invoice:id", inv.Number)
+	x.owner("invoice:owner", &inv.Owner)
+	x.timespec("invoice:opened", inv.Opened)
+	x.optTimespec("invoice:posted", inv.Posted)
+	x.optGUID("invoice:terms", inv.TermsID)
+	x.optText("invoice:billing_id", inv.BillingID)
+	x.optText("invoice:notes", inv.Notes)
+	x.boolean("invoice:active", inv.Active)
+	x.optGUID("invoice:posttxn", inv.PostTxnID)
+	x.optGUID("invoice:postlot", inv.PostLotID)
+	x.optGUID("invoice:postacc", inv.PostAccID)
+	x.commodityRef("invoice:currency", inv.Currency)
+	x.optOwner("invoice:billto", inv.BillTo)
+	x.optNumeric("invoice:charge-amt", &inv.ChargeAmount)
+	x.slots("invoice:slots", inv.Slots)
+	x.objEnd("gnc:GncInvoice", &inv.Unknown)
+}
+
+func (x *xmlWriter) job(j *Job) {
+	x.objStart("gnc:GncJob", &j.Unknown, "version", "2.0.0")
+	x.guid("job:guid", j.ID)
+	x.text("job:id", j.Number)
+	x.text("job:name", j.Name)
+	x.optText("job:reference", j.Reference)
+	x.owner("job:owner", &j.Owner)
+	x.boolean("job:active", j.Active)
+	x.objEnd("gnc:GncJob", &j.Unknown)
+}
+
+func (x *xmlWriter) order(o *Order) {
+	x.objStart("gnc:GncOrder", &o.Unknown, "version", "2.0.0")
+	x.guid("order:guid", o.ID)
+	x.text("order:id", o.Number)
+	x.owner("order:owner", &o.Owner)
+	x.timespec("order:opened", o.Opened)
+	x.optTimespec("order:closed", o.Closed)
+	x.optText("order:notes", o.Notes)
+	x.optText("order:reference", o.Reference)
+	x.boolean("order:active", o.Active)
+	x.objEnd("gnc:GncOrder", &o.Unknown)
+}
+
+func (x *xmlWriter) taxTable(tt *TaxTable) {
+	x.objStart("gnc:GncTaxTable", &tt.Unknown, "version", "2.0.0")
+	x.optGUID("taxtable:guid", tt.ID)
+	x.text("taxtable:name", tt.Name)
+	x.integer("taxtable:refcount", int64(tt.RefCount))
+	x.boolean("taxtable:invisible", tt.Invisible)
+	x.optGUID("taxtable:child", tt.ChildID)
+	x.optGUID("taxtable:parent", tt.ParentID)
+	if len(tt.Entries) == 0 {
+		x.empty("taxtable:entries")
+	} else {
+		x.start("taxtable:entries")
+		for _, tte := range tt.Entries {
+			x.start("gnc:GncTaxTableEntry")
+			x.optGUID("tte:acct", tte.AccountID)
+			x.numeric("tte:amount", &tte.Amount)
+			x.text("tte:type", string(tte.Type))
+			x.end("gnc:GncTaxTableEntry")
+		}
+		x.end("taxtable:entries")
+	}
+	x.objEnd("gnc:GncTaxTable", &tt.Unknown)
+}
+
+func (x *xmlWriter) vendor(v *Vendor) {
+	x.objStart("gnc:GncVendor", &v.Unknown, "version", "2.0.0")
+	x.guid("vendor:guid", v.ID)
+	x.text("vendor:name", v.Name)
+	x.text("vendor:id", v.Number)
+	x.address("vendor:addr", &v.Addr)
+	x.optText("vendor:notes", v.Notes)
+	x.optGUID("vendor:terms", v.TermsID)
+	x.text("vendor:taxincluded", v.TaxIncluded)
+	x.boolean("vendor:active", v.Active)
+	x.commodityRef("vendor:currency", v.Currency)
+	x.boolean("vendor:use-tt", v.UseTaxTable)
+	x.optGUID("vendor:taxtable", v.TaxTableID)
+	x.slots("vendor:slots", v.Slots)
+	x.objEnd("gnc:GncVendor", &v.Unknown)
+}
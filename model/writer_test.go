@@ -0,0 +1,350 @@
+package model
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// writeTestBook writes the book to a string.
+func writeTestBook(t *testing.T, book *Book) string {
+	var buf bytes.Buffer
+	if err := Write(&buf, &Gnc{Book: book}); err != nil {
+		t.Fatalf("Write: unexpected error: %s", err)
+	}
+	return buf.String()
+}
+
+// testQuotesBook has a commodity without fraction, whose quotes are in
+// the local time zone.
+const testQuotesBook = `<gnc:commodity version="2.0.0">
+  <cmdty:space>NYSE</cmdty:space>
+  <cmdty:id>XYZ</cmdty:id>
+  <cmdty:get_quotes/>
+  <cmdty:quote_source>yahoo_json</cmdty:quote_source>
+  <cmdty:quote_tz/>
+</gnc:commodity>
+`
+
+// objectFields appends to lines the values of the fields of the object
+// pointed by v, as "obj.field=value". The references to other objects
+// and the elements not understood by the decoder are skipped.
+func objectFields(lines []string, obj string, v interface{}) []string {
+	rv := reflect.ValueOf(v).Elem()
+	for j := 0; j < rv.NumField(); j++ {
+		f, fv := rv.Type().Field(j), rv.Field(j)
+		if f.PkgPath != "" {
+			continue
+		}
+		var s string
+		switch x := fv.Interface().(type) {
+		case types.Numeric:
+			s = types.Reduce(&x).String()
+		case *Commodity:
+			if x != nil {
+				s = x.Space + ":" + x.ID
+			}
+		case *string:
+			s = "<nil>"
+			if x != nil {
+				s = strconv.Quote(*x)
+			}
+		case fmt.Stringer:
+			if fv.Kind() == reflect.Ptr {
+				continue
+			}
+			s = x.String()
+		default:
+			switch fv.Kind() {
+			case reflect.Ptr, reflect.Struct, reflect.Slice, reflect.Map, reflect.Interface:
+				continue
+			}
+			s = fmt.Sprint(x)
+		}
+		lines = append(lines, obj+"."+f.Name+"="+s)
+	}
+	return lines
+}
+
+// bookFields returns the values of the fields of the book and of its
+// objects, as returned by objectFields.
+func bookFields(b *Book) []string {
+	lines := objectFields(nil, "book", b)
+
+	lists := []interface{}{
+		b.Commodities, b.PriceDB.Prices, b.Accounts.List, b.Transactions,
+		b.TemplateAccounts.List, b.TemplateTransactions, b.ScheduledTransactions, b.Budgets,
+		b.Business.Customers, b.Business.Vendors, b.Business.Employees, b.Business.Jobs,
+		b.Business.Invoices, b.Business.Entries, b.Business.Orders, b.Business.BillTerms,
+		b.Business.TaxTables,
+	}
+	for _, acc := range append(b.Accounts.List, b.TemplateAccounts.List...) {
+		lists = append(lists, acc.Lots)
+	}
+	for _, t := range append(b.Transactions, b.TemplateTransactions...) {
+		lists = append(lists, t.Splits)
+	}
+
+	for _, list := range lists {
+		rv := reflect.ValueOf(list)
+		for j := 0; j < rv.Len(); j++ {
+			obj := rv.Index(j).Interface()
+			lines = objectFields(lines, fmt.Sprintf("%T[%d]", obj, j), obj)
+		}
+	}
+	return lines
+}
+
+// reEmptyText matches the elements with empty text content,
+// as written by xmlWriter.text.
+var reEmptyText = regexp.MustCompile(`<([\w:-]+)(?: [^>]*)?></([\w:-]+)>`)
+
+// reEmpty matches the empty elements.
+var reEmpty = regexp.MustCompile(`<([\w:-]+)(?: [^>]*)?/>`)
+
+func TestWriteRoundTrip(t *testing.T) {
+	var testCases = []struct {
+		name string
+		body string
+	}{
+		{"transactions", testTransactionsBook},
+		{"lots", testLotsBook},
+		{"prices", testPriceDB},
+		{"slots", testSlotsBook},
+		{"scheduled", testScheduledBook},
+		{"budget", testBudgetBook},
+		{"business", testBusinessBook},
+		{"invoice", testInvoiceBook},
+		{"quotes", testQuotesBook},
+	}
+
+	for _, tc := range testCases {
+		book1 := readTestBook(t, tc.body)
+		out1 := writeTestBook(t, book1)
+
+		// the elements written empty must be empty in the input
+		in := testXMLHeader + tc.body + testXMLFooter
+		empty := map[string]bool{}
+		for _, m := range append(reEmptyText.FindAllStringSubmatch(in, -1), reEmpty.FindAllStringSubmatch(in, -1)...) {
+			empty[m[1]] = true
+		}
+		for _, m := range reEmptyText.FindAllStringSubmatch(out1, -1) {
+			if !empty[m[1]] {
+				t.Errorf("%s: element %s written empty", tc.name, m[1])
+			}
+		}
+
+		var gnc Gnc
+		if err := xml.Unmarshal([]byte(out1), &gnc); err != nil {
+			t.Errorf("%s: unexpected error reading the written book: %s\n%s", tc.name, err, out1)
+			continue
+		}
+		book2 := gnc.Book
+		out2 := writeTestBook(t, book2)
+
+		if out1 != out2 {
+			t.Errorf("%s: round trip mismatch:\n%s\n---\n%s", tc.name, out1, out2)
+		}
+
+		fields1, fields2 := bookFields(book1), bookFields(book2)
+		for j := 0; j < len(fields1) || j < len(fields2); j++ {
+			var f1, f2 string
+			if j < len(fields1) {
+				f1 = fields1[j]
+			}
+			if j < len(fields2) {
+				f2 = fields2[j]
+			}
+			if f1 != f2 {
+				t.Errorf("%s: expected %s, got %s", tc.name, f1, f2)
+				break
+			}
+		}
+
+		for _, c := range []struct {
+			what   string
+			n1, n2 int
+		}{
+			{"commodities", book1.Commodities.Len(), book2.Commodities.Len()},
+			{"accounts", book1.Accounts.Len(), book2.Accounts.Len()},
+			{"transactions", book1.Transactions.Len(), book2.Transactions.Len()},
+			{"prices", book1.PriceDB.Len(), book2.PriceDB.Len()},
+			{"scheduled", book1.ScheduledTransactions.Len(), book2.ScheduledTransactions.Len()},
+			{"budgets", book1.Budgets.Len(), book2.Budgets.Len()},
+			{"business", book1.Business.Len(), book2.Business.Len()},
+			{"slots", book1.Slots.Len(), book2.Slots.Len()},
+		} {
+			if c.n1 != c.n2 {
+				t.Errorf("%s: %s count, expected %d, got %d", tc.name, c.what, c.n1, c.n2)
+			}
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	book := readTestBook(t, testInvoiceBook)
+	expected := writeTestBook(t, book)
+	dir := t.TempDir()
+
+	for _, opts := range []*WriteOptions{nil, {Plain: true}} {
+		path := filepath.Join(dir, "test.gnucash")
+		if err := WriteFile(path, &Gnc{Book: book}, opts); err != nil {
+			t.Fatalf("WriteFile: unexpected error: %s", err)
+		}
+		if opts != nil && opts.Plain {
+			buf, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(buf) != expected {
+				t.Errorf("WriteFile: plain file mismatch:\n%s\n---\n%s", expected, buf)
+			}
+		}
+		gnc, err := ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: unexpected error: %s", err)
+		}
+		if actual := writeTestBook(t, gnc.Book); actual != expected {
+			t.Errorf("WriteFile: mismatch after reading back:\n%s\n---\n%s", expected, actual)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	book := readTestBook(t, testInvoiceBook)
+	expected := writeTestBook(t, book)
+
+	for _, plain := range []bool{false, true} {
+		var buf bytes.Buffer
+		if plain {
+			buf.WriteString(expected)
+		} else {
+			zw := gzip.NewWriter(&buf)
+			zw.Write([]byte(expected))
+			zw.Close()
+		}
+		gnc, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("Read(plain=%v): unexpected error: %s", plain, err)
+		}
+		if actual := writeTestBook(t, gnc.Book); actual != expected {
+			t.Errorf("Read(plain=%v): mismatch:\n%s\n---\n%s", plain, expected, actual)
+		}
+	}
+
+	if _, err := Read(strings.NewReader("\x1f\x8bnot gzip")); err == nil {
+		t.Errorf("Read: expected error for a corrupted gzip stream")
+	}
+}
+
+const testUnknownBook = `<plg:settings plg:scope="book"><plg:a>1</plg:a></plg:settings>
+<gnc:account version="2.0.0" plg:flag="1">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">10000000000000000000000000000001</act:id>
+  <act:type>ROOT</act:type>
+  <plg:color>red</plg:color>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Bank</act:name>
+  <act:id type="guid">10000000000000000000000000000002</act:id>
+  <act:type>BANK</act:type>
+  <act:future/>
+  <act:parent type="guid">10000000000000000000000000000001</act:parent>
+</gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">20000000000000000000000000000001</trn:id>
+  <trn:date-posted><ts:date>2016-01-10 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:date-entered><ts:date>2016-01-10 10:00:00 +0100</ts:date></trn:date-entered>
+  <trn:description>Deposit</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">30000000000000000000000000000001</split:id>
+      <split:reconciled-state>n</split:reconciled-state>
+      <split:value>100/1</split:value>
+      <split:quantity>100/1</split:quantity>
+      <split:account type="guid">10000000000000000000000000000002</split:account>
+      <other xmlns="http://example.com/other">x<y/></other>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:GncCustomer version="2.0.0">
+  <cust:guid type="guid">40000000000000000000000000000001</cust:guid>
+  <cust:name>Acme</cust:name>
+  <cust:id>000001</cust:id>
+  <cust:addr version="2.0.0"/>
+  <cust:active>1</cust:active>
+  <cust:loyalty>gold</cust:loyalty>
+</gnc:GncCustomer>
+`
+
+func TestWriteUnknown(t *testing.T) {
+	const plugin = `xmlns:plg="http://example.com/plugin"`
+	header := strings.Replace(testXMLHeader, "<gnc-v2", "<gnc-v2 "+plugin, 1)
+
+	var gnc1 Gnc
+	if err := xml.Unmarshal([]byte(header+testUnknownBook+testXMLFooter), &gnc1); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]int{
+		"gnc:book/{http://example.com/plugin}settings": 1,
+		"gnc:account/@{http://example.com/plugin}flag": 1,
+		"gnc:account/{http://example.com/plugin}color": 1,
+		"gnc:account/act:future":                       1,
+		"trn:split/{http://example.com/other}other":    1,
+		"gnc:GncCustomer/cust:loyalty":                 1,
+	}
+	if actual := gnc1.Book.Preserved(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Preserved: expected %v, got %v", expected, actual)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, &gnc1); err != nil {
+		t.Fatalf("Write: unexpected error: %s", err)
+	}
+	out1 := buf.String()
+	for _, s := range []string{
+		plugin,
+		`<plg:settings plg:scope="book"><plg:a>1</plg:a></plg:settings>`,
+		`<gnc:account version="2.0.0" plg:flag="1">`,
+		`<plg:color>red</plg:color>`,
+		`<act:future/>`,
+		`<other xmlns="http://example.com/other">x<y/></other>`,
+		`<cust:loyalty>gold</cust:loyalty>`,
+	} {
+		if !strings.Contains(out1, s) {
+			t.Errorf("Write: %q not found in\n%s", s, out1)
+		}
+	}
+
+	var gnc2 Gnc
+	if err := xml.Unmarshal([]byte(out1), &gnc2); err != nil {
+		t.Fatalf("unexpected error reading the written book: %s\n%s", err, out1)
+	}
+	if actual := gnc2.Book.Preserved(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Preserved after write: expected %v, got %v", expected, actual)
+	}
+	buf.Reset()
+	if err := Write(&buf, &gnc2); err != nil {
+		t.Fatalf("Write: unexpected error: %s", err)
+	}
+	if out2 := buf.String(); out1 != out2 {
+		t.Errorf("round trip mismatch:\n%s\n---\n%s", out1, out2)
+	}
+
+	for _, tc := range []string{testTransactionsBook, testBusinessBook, testInvoiceBook} {
+		if m := readTestBook(t, tc).Preserved(); len(m) != 0 {
+			t.Errorf("Preserved: unexpected elements %v", m)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Package render renders business documents of a GnuCash book to HTML.
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/report"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// Template file names, looked up in the template directory.
+const (
+	InvoiceTemplate   = "invoice.html"
+	StatementTemplate = "statement.html"
+)
+
+// defaultTemplates are used when the template directory
+// does not provide the corresponding file.
+var defaultTemplates = map[string]string{
+	InvoiceTemplate:   defaultInvoiceTemplate,
+	StatementTemplate: defaultStatementTemplate,
+}
+
+// Renderer renders invoices and statements with html/template.
+type Renderer struct {
+	templates map[string]*template.Template
+}
+
+// New returns a renderer using the templates found in dir, if any,
+// and the default ones otherwise. An empty dir means default templates only.
+func New(dir string) (*Renderer, error) {
+	r := &Renderer{templates: map[string]*template.Template{}}
+	for name, text := range defaultTemplates {
+		if dir != "" {
+			buf, err := os.ReadFile(filepath.Join(dir, name))
+			switch {
+			case err == nil:
+				text = string(buf)
+			case !os.IsNotExist(err):
+				return nil, err
+			}
+		}
+		t, err := template.New(name).Funcs(funcs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		r.templates[name] = t
+	}
+	return r, nil
+}
+
+// funcs are the functions available to the templates.
+var funcs = template.FuncMap{
+	// amount formats a numeric with the decimals of the commodity.
+	"amount": func(cmdty *model.Commodity, n interface{}) string {
+		switch x := n.(type) {
+		case *types.Numeric:
+			return cmdty.FormatAmount(x)
+		case types.Numeric:
+			return cmdty.FormatAmount(&x)
+		}
+		return fmt.Sprint(n)
+	},
+	// neg returns the opposite of a numeric.
+	"neg": func(n types.Numeric) *types.Numeric {
+		return types.Neg(&n)
+	},
+	// number formats a numeric with the needed decimals only.
+	"number": func(n types.Numeric) string {
+		return strconv.FormatFloat(n.Float64(), 'f', -1, 64)
+	},
+	// date formats a time.Time or types.Timespec as YYYY-MM-DD.
+	"date": func(t interface{}) string {
+		var d time.Time
+		switch x := t.(type) {
+		case time.Time:
+			d = x
+		case types.Timespec:
+			d = time.Time(x)
+		}
+		if d.IsZero() {
+			return ""
+		}
+		return d.Format("2006-01-02")
+	},
+}
+
+// InvoiceLine is an entry of an invoice with its computed amounts.
+type InvoiceLine struct {
+	Entry    *model.Entry
+	Price    types.Numeric
+	Discount *types.Numeric
+	Value    *types.Numeric
+	Tax      *types.Numeric
+}
+
+// InvoiceData is the data passed to the invoice template.
+type InvoiceData struct {
+	Title    string
+	Company  model.Address
+	Invoice  *model.Invoice
+	Currency *model.Commodity
+	// To is the address of the owner of the invoice.
+	To       model.Address
+	Lines    []*InvoiceLine
+	Subtotal *types.Numeric
+	Tax      *types.Numeric
+	Total    *types.Numeric
+	DueDate  time.Time
+}
+
+// StatementData is the data passed to the statement template.
+type StatementData struct {
+	Company   model.Address
+	Statement *report.Statement
+	Currency  *model.Commodity
+	To        model.Address
+}
+
+// ownerAddress returns the address of the end owner.
+func ownerAddress(o *model.Owner) model.Address {
+	o = o.End()
+	switch {
+	case o.Customer != nil:
+		return o.Customer.Addr
+	case o.Vendor != nil:
+		return o.Vendor.Addr
+	case o.Employee != nil:
+		return o.Employee.Addr
+	}
+	return model.Address{}
+}
+
+// Invoice writes the HTML of the invoice to w.
+func (r *Renderer) Invoice(w io.Writer, book *model.Book, inv *model.Invoice) error {
+	data := &InvoiceData{
+		Company:  book.Company(),
+		Invoice:  inv,
+		Currency: inv.Currency,
+		To:       ownerAddress(&inv.Owner),
+		Subtotal: inv.Subtotal(),
+		Tax:      inv.TaxTotal(),
+		Total:    inv.Total(),
+	}
+	if inv.IsPosted() {
+		data.DueDate = inv.DueDate()
+	}
+
+	cust := inv.IsCustomerDoc()
+	switch {
+	case inv.IsCreditNote():
+		data.Title = "Credit Note"
+	case cust:
+		data.Title = "Invoice"
+	case inv.Owner.End().Type == model.OwnerTypeEmployee:
+		data.Title = "Expense Voucher"
+	default:
+		data.Title = "Bill"
+	}
+
+	for _, e := range inv.Entries {
+		line := &InvoiceLine{Entry: e}
+		var ea model.EntryAmounts
+		if cust {
+			ea = e.InvoiceAmounts()
+			line.Price = e.InvPrice
+		} else {
+			ea = e.BillAmounts()
+			line.Price = e.BillPrice
+		}
+		line.Discount, line.Value, line.Tax = ea.Discount, ea.Value, ea.Tax
+		data.Lines = append(data.Lines, line)
+	}
+
+	return r.templates[InvoiceTemplate].Execute(w, data)
+}
+
+// Statement writes the HTML of the statement to w.
+func (r *Renderer) Statement(w io.Writer, book *model.Book, st *report.Statement) error {
+	data := &StatementData{
+		Company:   book.Company(),
+		Statement: st,
+		Currency:  st.Customer.Currency,
+		To:        st.Customer.Addr,
+	}
+	return r.templates[StatementTemplate].Execute(w, data)
+}
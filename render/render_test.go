@@ -0,0 +1,91 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/report"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+func testInvoice() (*model.Book, *model.Invoice) {
+	eur := &model.Commodity{Space: "ISO4217", ID: "EUR"}
+	cust := &model.Customer{
+		Name:     "Acme <Srl>",
+		Addr:     model.Address{Name: "Acme <Srl>", Addr1: "Via Roma 1"},
+		Currency: eur,
+	}
+	inv := &model.Invoice{
+		Number:   "000042",
+		Owner:    model.Owner{Type: model.OwnerTypeCustomer, Customer: cust},
+		Posted:   types.Timespec(time.Date(2016, 3, 10, 0, 0, 0, 0, time.UTC)),
+		Currency: eur,
+	}
+	inv.Entries = []*model.Entry{
+		{Description: "Consulting", Quantity: *types.New(3, 2), InvPrice: *types.New(10000, 100)},
+	}
+	return &model.Book{}, inv
+}
+
+func TestInvoice(t *testing.T) {
+	book, inv := testInvoice()
+	r, err := New("")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var buf bytes.Buffer
+	if err := r.Invoice(&buf, book, inv); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	html := buf.String()
+	for _, s := range []string{
+		"<h1>Invoice 000042</h1>",
+		"Acme &lt;Srl&gt;<br>",
+		"Via Roma 1<br>",
+		"<td>Consulting</td>",
+		`<td class="num">1.5</td>`,
+		`<td class="num">100.00</td>`,
+		"<strong>150.00</strong>",
+		"2016-03-10",
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("Invoice: expected %q in output:\n%s", s, html)
+		}
+	}
+}
+
+func TestTemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	text := `{{.Title}} {{.Invoice.Number}}: {{amount .Currency .Total}} {{.Currency}}`
+	if err := os.WriteFile(filepath.Join(dir, InvoiceTemplate), []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	book, inv := testInvoice()
+	var buf bytes.Buffer
+	if err := r.Invoice(&buf, book, inv); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := "Invoice 000042: 150.00 EUR"; buf.String() != expected {
+		t.Errorf("Invoice: expected %q, got %q", expected, buf.String())
+	}
+
+	// the statement template is not overridden
+	st := &report.Statement{Customer: inv.Owner.Customer}
+	buf.Reset()
+	if err := r.Statement(&buf, book, st); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(buf.String(), "<h1>Statement of account</h1>") {
+		t.Errorf("Statement: expected the default template, got:\n%s", buf.String())
+	}
+}
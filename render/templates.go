@@ -0,0 +1,110 @@
+package render
+
+// defaultInvoiceTemplate is the default template of invoices,
+// bills and vouchers: data is *InvoiceData.
+const defaultInvoiceTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Invoice.Number}}</title>
+<style>
+body { font-family: sans-serif; font-size: 10pt; margin: 2em; }
+table.lines { border-collapse: collapse; width: 100%; margin-top: 2em; }
+table.lines th, table.lines td { border-bottom: 1px solid #ccc; padding: 4px; }
+td.num, th.num { text-align: right; }
+.address { margin-top: 1em; }
+</style>
+</head>
+<body>
+<div class="address company">
+{{with .Company}}<strong>{{.Name}}</strong><br>
+{{if .Addr1}}{{.Addr1}}<br>{{end}}{{if .Addr2}}{{.Addr2}}<br>{{end}}{{if .Addr3}}{{.Addr3}}<br>{{end}}{{if .Addr4}}{{.Addr4}}<br>{{end}}
+{{if .Phone}}Phone: {{.Phone}}<br>{{end}}{{if .Email}}{{.Email}}<br>{{end}}{{end}}
+</div>
+
+<h1>{{.Title}} {{.Invoice.Number}}</h1>
+
+<div class="address to">
+{{range .To.Lines}}{{.}}<br>
+{{end}}</div>
+
+<table class="info">
+<tr><td>Date:</td><td>{{date .Invoice.Posted}}</td></tr>
+{{if not .DueDate.IsZero}}<tr><td>Due date:</td><td>{{date .DueDate}}</td></tr>{{end}}
+{{if .Invoice.BillingID}}<tr><td>Reference:</td><td>{{.Invoice.BillingID}}</td></tr>{{end}}
+{{if .Invoice.Owner.Job}}<tr><td>Job:</td><td>{{.Invoice.Owner.Job.Name}}</td></tr>{{end}}
+</table>
+
+<table class="lines">
+<tr>
+<th>Date</th><th>Description</th><th>Action</th>
+<th class="num">Quantity</th><th class="num">Price</th>
+<th class="num">Discount</th><th class="num">Tax</th><th class="num">Amount</th>
+</tr>
+{{range .Lines}}<tr>
+<td>{{date .Entry.Date}}</td><td>{{.Entry.Description}}</td><td>{{.Entry.Action}}</td>
+<td class="num">{{number .Entry.Quantity}}</td><td class="num">{{amount $.Currency .Price}}</td>
+<td class="num">{{amount $.Currency .Discount}}</td><td class="num">{{amount $.Currency .Tax}}</td>
+<td class="num">{{amount $.Currency .Value}}</td>
+</tr>
+{{end}}<tr><td colspan="7" class="num">Subtotal</td><td class="num">{{amount .Currency .Subtotal}}</td></tr>
+<tr><td colspan="7" class="num">Tax</td><td class="num">{{amount .Currency .Tax}}</td></tr>
+<tr><td colspan="7" class="num"><strong>Total {{.Currency}}</strong></td><td class="num"><strong>{{amount .Currency .Total}}</strong></td></tr>
+</table>
+
+{{if .Invoice.Notes}}<p class="notes">{{.Invoice.Notes}}</p>{{end}}
+</body>
+</html>
+`
+
+// defaultStatementTemplate is the default template of customer
+// statements: data is *StatementData.
+const defaultStatementTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Statement {{.Statement.Customer.Name}}</title>
+<style>
+body { font-family: sans-serif; font-size: 10pt; margin: 2em; }
+table.lines { border-collapse: collapse; width: 100%; margin-top: 2em; }
+table.lines th, table.lines td { border-bottom: 1px solid #ccc; padding: 4px; }
+td.num, th.num { text-align: right; }
+.address { margin-top: 1em; }
+</style>
+</head>
+<body>
+<div class="address company">
+{{with .Company}}<strong>{{.Name}}</strong><br>
+{{if .Addr1}}{{.Addr1}}<br>{{end}}{{if .Addr2}}{{.Addr2}}<br>{{end}}{{if .Addr3}}{{.Addr3}}<br>{{end}}{{if .Addr4}}{{.Addr4}}<br>{{end}}
+{{if .Phone}}Phone: {{.Phone}}<br>{{end}}{{if .Email}}{{.Email}}<br>{{end}}{{end}}
+</div>
+
+<h1>Statement of account</h1>
+
+<div class="address to">
+{{range .To.Lines}}{{.}}<br>
+{{end}}</div>
+
+<p>From {{date .Statement.From}} to {{date .Statement.To}}</p>
+
+<table class="lines">
+<tr>
+<th>Date</th><th>Reference</th><th>Description</th>
+<th class="num">Charges</th><th class="num">Payments</th><th class="num">Balance</th>
+</tr>
+<tr><td>{{date .Statement.From}}</td><td></td><td>Opening balance</td><td></td><td></td>
+<td class="num">{{amount .Currency .Statement.Opening}}</td></tr>
+{{range .Statement.Lines}}<tr>
+<td>{{date .Date}}</td>
+{{if .Invoice}}<td>Invoice {{.Invoice.Number}}</td><td>{{.Description}}</td>
+<td class="num">{{amount $.Currency .Amount}}</td><td></td>
+{{else}}<td>Payment</td><td>{{.Description}}</td>
+<td></td><td class="num">{{amount $.Currency (neg .Amount)}}</td>
+{{end}}<td class="num">{{amount $.Currency .Balance}}</td>
+</tr>
+{{end}}<tr><td>{{date .Statement.To}}</td><td></td><td><strong>Closing balance</strong></td><td></td><td></td>
+<td class="num"><strong>{{amount .Currency .Statement.Closing}}</strong></td></tr>
+</table>
+</body>
+</html>
+`
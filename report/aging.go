@@ -0,0 +1,156 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// Aging buckets, by days past the due date.
+const (
+	// BucketCurrent holds the amounts not yet due.
+	BucketCurrent = iota
+	Bucket0To30
+	Bucket31To60
+	Bucket61To90
+	BucketOver90
+	// NumBuckets is the number of aging buckets.
+	NumBuckets
+)
+
+// BucketLabels are the labels of the aging buckets.
+var BucketLabels = [NumBuckets]string{"Current", "0-30", "31-60", "61-90", "90+"}
+
+// AgingRow is the aging of the open invoices and of the unapplied payments
+// of a customer, vendor or employee.
+type AgingRow struct {
+	Owner   *model.Owner
+	Buckets [NumBuckets]types.Numeric
+	Total   types.Numeric
+}
+
+// AgingReport is the aging of the amounts still due in the accounts
+// receivable or payable at a given date.
+type AgingReport struct {
+	// Date is the date of the report.
+	Date time.Time
+	// Type is either types.AccountTypeReceivable or types.AccountTypePayable.
+	Type types.AccountType
+	// Rows has one row per owner with an amount due, sorted by name.
+	Rows    []*AgingRow
+	Buckets [NumBuckets]types.Numeric
+	Total   types.Numeric
+}
+
+// NewAgingReport returns the aging report of the accounts of the given
+// type (receivable or payable) at the given date.
+// The amount due of each invoice is the balance of its post lot at date,
+// aged by the due date of the invoice. The pre-payments and the payments
+// not applied to an invoice are the balance of the other lots of the
+// accounts with an owner, aged by the date of the payment: they are
+// negative, so that the totals of the owners match the account balances.
+// Amounts are positive when due to (receivable) or by (payable) the book.
+func NewAgingReport(book *model.Book, accType types.AccountType, date time.Time) *AgingReport {
+	date = dateOf(date)
+	rpt := &AgingReport{Date: date, Type: accType}
+	rows := map[types.GUID]*AgingRow{}
+	add := func(owner *model.Owner, b int, due *types.Numeric) {
+		row, ok := rows[owner.ID]
+		if !ok {
+			row = &AgingRow{Owner: owner}
+			rows[owner.ID] = row
+			rpt.Rows = append(rpt.Rows, row)
+		}
+		row.Buckets[b].AddEqual(due)
+		row.Total.AddEqual(due)
+		rpt.Buckets[b].AddEqual(due)
+		rpt.Total.AddEqual(due)
+	}
+
+	// the post lots of the invoices
+	postLots := map[*model.Lot]bool{}
+	for _, inv := range book.Business.Invoices {
+		if inv.PostLot != nil {
+			postLots[inv.PostLot] = true
+		}
+		if inv.PostLot == nil || inv.PostAccount == nil || inv.PostAccount.Type != accType {
+			continue
+		}
+		if dateOf(time.Time(inv.Posted)).After(date) {
+			continue
+		}
+		due := lotBalance(inv.PostLot, date)
+		if accType == types.AccountTypePayable {
+			due.NegEqual()
+		}
+		if due.IsZero() {
+			continue
+		}
+
+		add(inv.Owner.End(), agingBucket(date, inv.DueDate()), due)
+	}
+
+	// the payment lots
+	for _, acc := range book.Accounts.List {
+		if acc.Type != accType {
+			continue
+		}
+		for _, lot := range acc.Lots {
+			if postLots[lot] || len(lot.Splits) == 0 {
+				continue
+			}
+			paid := time.Time(lot.Splits[0].Transaction.DatePosted)
+			if dateOf(paid).After(date) {
+				continue
+			}
+			owner := book.Business.LotOwner(lot)
+			if owner == nil {
+				continue
+			}
+			due := lotBalance(lot, date)
+			if accType == types.AccountTypePayable {
+				due.NegEqual()
+			}
+			if due.IsZero() {
+				continue
+			}
+			add(owner, agingBucket(date, paid), due)
+		}
+	}
+
+	sort.Slice(rpt.Rows, func(i, j int) bool {
+		return rpt.Rows[i].Owner.Name() < rpt.Rows[j].Owner.Name()
+	})
+	return rpt
+}
+
+// lotBalance returns the sum of the quantities of the splits of the lot
+// posted until date included.
+func lotBalance(lot *model.Lot, date time.Time) *types.Numeric {
+	n := &types.Numeric{}
+	for _, s := range lot.Splits {
+		if s.Transaction != nil && dateOf(time.Time(s.Transaction.DatePosted)).After(date) {
+			continue
+		}
+		n.AddEqual(&s.Quantity)
+	}
+	return n
+}
+
+// agingBucket returns the bucket of an amount with the given due date.
+func agingBucket(date, due time.Time) int {
+	days := int(date.Sub(dateOf(due)).Hours() / 24)
+	switch {
+	case days <= 0:
+		return BucketCurrent
+	case days <= 30:
+		return Bucket0To30
+	case days <= 60:
+		return Bucket31To60
+	case days <= 90:
+		return Bucket61To90
+	}
+	return BucketOver90
+}
@@ -0,0 +1,124 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+func TestAgingReport(t *testing.T) {
+	ar := &model.Account{Name: "A/R", Type: types.AccountTypeReceivable}
+	ap := &model.Account{Name: "A/P", Type: types.AccountTypePayable}
+	net30 := &model.BillTerm{Days: &model.BillTermDays{DueDays: 30}}
+
+	acme := &model.Customer{ID: "c1", Name: "Acme"}
+	beta := &model.Customer{ID: "c2", Name: "Beta"}
+	web := &model.Job{ID: "j1", Owner: model.Owner{Type: model.OwnerTypeCustomer, ID: "c1", Customer: acme}}
+	host := &model.Vendor{ID: "v1", Name: "Hosting"}
+
+	book := &model.Book{}
+	addInvoice := func(owner model.Owner, acc *model.Account, posted string, terms *model.BillTerm, amounts ...string) {
+		lot := &model.Lot{Account: acc}
+		inv := &model.Invoice{Owner: owner, Posted: date(posted), Terms: terms, PostAccount: acc, PostLot: lot}
+		addLotSplit(lot, posted, amounts[0], amounts[0])
+		inv.PostTxn = lot.Splits[0].Transaction
+		for j := 1; j < len(amounts); j += 2 {
+			addLotSplit(lot, amounts[j], amounts[j+1], amounts[j+1])
+		}
+		book.Business.Invoices = append(book.Business.Invoices, inv)
+	}
+	acmeOwner := model.Owner{Type: model.OwnerTypeCustomer, ID: "c1", Customer: acme}
+	betaOwner := model.Owner{Type: model.OwnerTypeCustomer, ID: "c2", Customer: beta}
+	webOwner := model.Owner{Type: model.OwnerTypeJob, ID: "j1", Job: web}
+	hostOwner := model.Owner{Type: model.OwnerTypeVendor, ID: "v1", Vendor: host}
+
+	// due 2016-04-09: 0-30 at 2016-05-01 (22 days)
+	addInvoice(acmeOwner, ar, "2016-03-10", net30, "100")
+	// job invoice, due on posting: 90+ (105 days)
+	addInvoice(webOwner, ar, "2016-01-17", nil, "50")
+	// partially paid, due 2016-05-30: current
+	addInvoice(betaOwner, ar, "2016-04-30", net30, "200", "2016-04-30", "-80")
+	// paid after the report date: still due, 31-60
+	addInvoice(betaOwner, ar, "2016-03-10", nil, "30", "2016-05-10", "-30")
+	// fully paid: not reported
+	addInvoice(betaOwner, ar, "2016-03-01", nil, "40", "2016-03-05", "-40")
+	// posted after the report date: not reported
+	addInvoice(acmeOwner, ar, "2016-06-01", nil, "70")
+	// vendor bill: 61-90 (70 days)
+	addInvoice(hostOwner, ap, "2016-02-21", nil, "-25")
+
+	asOf := time.Date(2016, 5, 1, 15, 0, 0, 0, time.UTC)
+	rpt := NewAgingReport(book, types.AccountTypeReceivable, asOf)
+
+	if len(rpt.Rows) != 2 {
+		t.Fatalf("AgingReport: expected 2 rows, got %d", len(rpt.Rows))
+	}
+	var testCases = []struct {
+		row      *AgingRow
+		name     string
+		expected [NumBuckets]float64
+		total    float64
+	}{
+		{rpt.Rows[0], "Acme", [NumBuckets]float64{0, 100, 0, 0, 50}, 150},
+		{rpt.Rows[1], "Beta", [NumBuckets]float64{120, 0, 30, 0, 0}, 150},
+	}
+	for _, tc := range testCases {
+		if tc.row.Owner.Name() != tc.name {
+			t.Errorf("AgingRow: expected %s, got %s", tc.name, tc.row.Owner.Name())
+		}
+		for b := range tc.expected {
+			if actual := tc.row.Buckets[b].Float64(); actual != tc.expected[b] {
+				t.Errorf("AgingRow %s [%s]: expected %v, got %v", tc.name, BucketLabels[b], tc.expected[b], actual)
+			}
+		}
+		if actual := tc.row.Total.Float64(); actual != tc.total {
+			t.Errorf("AgingRow %s total: expected %v, got %v", tc.name, tc.total, actual)
+		}
+	}
+	if actual := rpt.Total.Float64(); actual != 300 {
+		t.Errorf("AgingReport total: expected 300, got %v", actual)
+	}
+
+	rpt = NewAgingReport(book, types.AccountTypePayable, asOf)
+	if len(rpt.Rows) != 1 || rpt.Rows[0].Owner.Vendor != host {
+		t.Fatalf("AgingReport payable: unexpected rows %v", rpt.Rows)
+	}
+	if actual := rpt.Rows[0].Buckets[Bucket61To90].Float64(); actual != 25 {
+		t.Errorf("AgingReport payable [61-90]: expected 25, got %v", actual)
+	}
+}
+
+func TestAgingReportPayments(t *testing.T) {
+	var gnc model.Gnc
+	if err := xml.NewDecoder(strings.NewReader(testStatementBook)).Decode(&gnc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	book := gnc.Book
+
+	// invoice 2 of 50, without posted date, and the unapplied payment
+	// of 20 of 2016-03-15: the total is the balance of the receivable account
+	rpt := NewAgingReport(book, types.AccountTypeReceivable, time.Date(2016, 4, 30, 0, 0, 0, 0, time.UTC))
+	if len(rpt.Rows) != 1 || rpt.Rows[0].Owner.Name() != "Acme Srl" {
+		t.Fatalf("AgingReport: unexpected rows %v", rpt.Rows)
+	}
+	row := rpt.Rows[0]
+	if actual := row.Buckets[Bucket31To60].Float64(); actual != -20 {
+		t.Errorf("AgingRow [31-60]: expected -20, got %v", actual)
+	}
+	if actual := row.Buckets[BucketOver90].Float64(); actual != 50 {
+		t.Errorf("AgingRow [90+]: expected 50, got %v", actual)
+	}
+	if actual := row.Total.Float64(); actual != 30 {
+		t.Errorf("AgingRow total: expected 30, got %v", actual)
+	}
+
+	// before the invoice 2 and the payment
+	rpt = NewAgingReport(book, types.AccountTypeReceivable, time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC))
+	if actual := rpt.Total.Float64(); actual != 100 {
+		t.Errorf("AgingReport total at 2016-03-01: expected 100, got %v", actual)
+	}
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// BudgetReport compares, for each period of a budget,
+// the budgeted amounts with the actual amounts.
+type BudgetReport struct {
+	Budget  *model.Budget
+	Periods []*BudgetPeriod
+}
+
+// BudgetPeriod is a period of a BudgetReport.
+type BudgetPeriod struct {
+	// Start is the first day of the period.
+	Start time.Time
+	// End is the first day after the period.
+	End time.Time
+	// Rows in account tree order.
+	Rows []*BudgetRow
+}
+
+// BudgetRow is the comparison of budgeted and actual amounts of an account
+// in a period. Amounts of parent accounts include the children amounts.
+type BudgetRow struct {
+	Account *model.Account
+	// Depth of the account in the tree: 0 for top level accounts.
+	Depth    int
+	Budgeted types.Numeric
+	// Actual is the sum of the split quantities of the period, with the
+	// sign inverted for accounts with InvertValues (ex: income),
+	// so that it can be compared with the budgeted amount.
+	Actual types.Numeric
+	// Difference is Budgeted - Actual.
+	Difference types.Numeric
+}
+
+// NewBudgetReport returns the budget-vs-actual report of the budget.
+// Only the accounts with a budgeted or actual amount are reported.
+func NewBudgetReport(book *model.Book, bgt *model.Budget) *BudgetReport {
+	rpt := &BudgetReport{Budget: bgt}
+
+	for period := 0; period < bgt.NumPeriods; period++ {
+		p := &BudgetPeriod{
+			Start: bgt.PeriodStart(period),
+			End:   bgt.PeriodEnd(period),
+		}
+
+		// actual amounts of every account, not rolled up
+		actual := map[*model.Account]*types.Numeric{}
+		for _, t := range book.Transactions {
+			posted := dateOf(time.Time(t.DatePosted))
+			if posted.Before(p.Start) || !posted.Before(p.End) {
+				continue
+			}
+			for _, s := range t.Splits {
+				a, ok := actual[s.Account]
+				if !ok {
+					a = &types.Numeric{}
+					actual[s.Account] = a
+				}
+				a.AddEqual(&s.Quantity)
+			}
+		}
+
+		// visit the account tree, rolling children up into parents
+		var visit func(acc *model.Account, depth int) *BudgetRow
+		visit = func(acc *model.Account, depth int) *BudgetRow {
+			row := &BudgetRow{Account: acc, Depth: depth}
+			if n := bgt.Amount(acc, period); n != nil {
+				row.Budgeted.AddEqual(n)
+			}
+			if n, ok := actual[acc]; ok {
+				if acc.Type.InvertValues() {
+					row.Actual.SubEqual(n)
+				} else {
+					row.Actual.AddEqual(n)
+				}
+			}
+			// the row must precede the children rows
+			idx := len(p.Rows)
+			p.Rows = append(p.Rows, row)
+			for _, child := range acc.Children {
+				cr := visit(child, depth+1)
+				row.Budgeted.AddEqual(&cr.Budgeted)
+				row.Actual.AddEqual(&cr.Actual)
+			}
+			row.Difference.Copy(types.Sub(&row.Budgeted, &row.Actual))
+			if row.Budgeted.IsZero() && row.Actual.IsZero() && len(p.Rows) == idx+1 {
+				// nothing to report for the account and its children
+				p.Rows = p.Rows[:idx]
+			}
+			return row
+		}
+		if root := book.Accounts.Root; root != nil {
+			for _, acc := range root.Children {
+				visit(acc, 0)
+			}
+		}
+
+		rpt.Periods = append(rpt.Periods, p)
+	}
+
+	return rpt
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// budgetSlot returns the budget slot of an account
+// with the given amounts, one for each period.
+func budgetSlot(acc *model.Account, amounts ...string) *model.Slot {
+	frame := model.Slots{}
+	for j, a := range amounts {
+		frame = append(frame, &model.Slot{
+			Key:   strconv.Itoa(j),
+			Value: &model.KvpValue{Type: model.KvpTypeNumeric, Numeric: num(a)},
+		})
+	}
+	return &model.Slot{
+		Key:   string(acc.ID),
+		Value: &model.KvpValue{Type: model.KvpTypeFrame, Frame: frame},
+	}
+}
+
+func TestBudgetReport(t *testing.T) {
+	root := &model.Account{ID: "r", Name: "Root", Type: types.AccountTypeRoot}
+	expenses := &model.Account{ID: "e", Name: "Expenses", Type: types.AccountTypeExpense, Parent: root}
+	food := &model.Account{ID: "f", Name: "Food", Type: types.AccountTypeExpense, Parent: expenses}
+	car := &model.Account{ID: "c", Name: "Car", Type: types.AccountTypeExpense, Parent: expenses}
+	salary := &model.Account{ID: "s", Name: "Salary", Type: types.AccountTypeIncome, Parent: root}
+	bank := &model.Account{ID: "b", Name: "Bank", Type: types.AccountTypeBank, Parent: root}
+	root.Children = []*model.Account{expenses, salary, bank}
+	expenses.Children = []*model.Account{food, car}
+
+	book := &model.Book{}
+	book.Accounts.Root = root
+	for _, acc := range []*model.Account{root, expenses, food, car, salary, bank} {
+		book.Accounts.Add(acc)
+	}
+
+	addTransaction := func(posted string, acc *model.Account, amount string) {
+		trn := &model.Transaction{DatePosted: date(posted)}
+		trn.Splits.Add(&model.Split{Account: acc, Quantity: num(amount)})
+		n := num(amount)
+		trn.Splits.Add(&model.Split{Account: bank, Quantity: *types.Neg(&n)})
+		book.Transactions.Add(trn)
+	}
+	addTransaction("2016-01-10", food, "120")
+	addTransaction("2016-01-20", food, "200")
+	addTransaction("2016-01-25", car, "50")
+	addTransaction("2016-01-27", salary, "-1500")
+	addTransaction("2016-02-10", food, "300")
+
+	bgt := &model.Budget{
+		Name:       "2016",
+		NumPeriods: 2,
+		Recurrence: types.Recurrence{Mult: 1, PeriodType: types.PeriodTypeMonth, Start: types.GDate(date("2016-01-01"))},
+		Slots: model.Slots{
+			budgetSlot(food, "400", "400"),
+			budgetSlot(salary, "1500", "1500"),
+		},
+	}
+
+	rpt := NewBudgetReport(book, bgt)
+	if len(rpt.Periods) != 2 {
+		t.Fatalf("Periods: expected 2, got %d", len(rpt.Periods))
+	}
+
+	type row struct {
+		name                         string
+		depth                        int
+		budgeted, actual, difference string
+	}
+	var testCases = []struct {
+		period int
+		rows   []row
+	}{
+		{0, []row{
+			{"Expenses", 0, "400", "370", "30"},
+			{"Food", 1, "400", "320", "80"},
+			{"Car", 1, "0", "50", "-50"},
+			{"Salary", 0, "1500", "1500", "0"},
+			{"Bank", 0, "0", "1130", "-1130"},
+		}},
+		{1, []row{
+			{"Expenses", 0, "400", "300", "100"},
+			{"Food", 1, "400", "300", "100"},
+			{"Salary", 0, "1500", "0", "1500"},
+			{"Bank", 0, "0", "-300", "300"},
+		}},
+	}
+	for _, tc := range testCases {
+		p := rpt.Periods[tc.period]
+		if len(p.Rows) != len(tc.rows) {
+			t.Errorf("Period %d: expected %d rows, got %d", tc.period, len(tc.rows), len(p.Rows))
+			continue
+		}
+		for j, expected := range tc.rows {
+			r := p.Rows[j]
+			actual := row{r.Account.Name, r.Depth, r.Budgeted.String(), r.Actual.String(), r.Difference.String()}
+			if actual != expected {
+				t.Errorf("Period %d, row %d: expected %v, got %v", tc.period, j, expected, actual)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Package report computes reports from a GnuCash book.
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// RealizedGain represents the gain realized by a closed lot.
+type RealizedGain struct {
+	Account *model.Account
+	Lot     *model.Lot
+
+	// Quantity is the amount of commodity acquired by the lot.
+	Quantity types.Numeric
+	// Acquired is the date of the first acquisition of the lot.
+	Acquired time.Time
+	// Disposed is the date of the last disposal of the lot.
+	Disposed time.Time
+
+	// CostBasis is the sum of the values of the acquisitions.
+	CostBasis types.Numeric
+	// Proceeds is the sum of the values of the disposals, as a positive amount.
+	Proceeds types.Numeric
+	// Gain is Proceeds - CostBasis.
+	Gain types.Numeric
+
+	// RecordedGain is the sum of the values of the gain splits of the lot,
+	// i.e. the splits with zero quantity written by the GnuCash lot scrubber.
+	// It equals Gain if the lot has been scrubbed.
+	RecordedGain types.Numeric
+}
+
+// HoldingPeriod returns the time between acquisition and disposal.
+func (g *RealizedGain) HoldingPeriod() time.Duration {
+	return g.Disposed.Sub(g.Acquired)
+}
+
+// LongTerm returns true if the lot was held for more than one year.
+func (g *RealizedGain) LongTerm() bool {
+	return g.Disposed.After(g.Acquired.AddDate(1, 0, 0))
+}
+
+// TaxYear groups the gains realized in the same year.
+type TaxYear struct {
+	Year  int
+	Gains []*RealizedGain
+	Total types.Numeric
+}
+
+// CapitalGains returns the gains realized by the closed lots of the
+// stock and mutual fund accounts of the book, grouped by the year of
+// disposal. Tax years are sorted by year, and gains by disposal date.
+func CapitalGains(book *model.Book) []*TaxYear {
+	years := map[int]*TaxYear{}
+
+	for _, acc := range book.Accounts.List {
+		if acc.Type != types.AccountTypeStock && acc.Type != types.AccountTypeMutual {
+			continue
+		}
+		for _, lot := range acc.Lots {
+			if !lot.IsClosed() {
+				continue
+			}
+			g := lotGain(lot)
+			y := g.Disposed.Year()
+			ty, ok := years[y]
+			if !ok {
+				ty = &TaxYear{Year: y}
+				years[y] = ty
+			}
+			ty.Gains = append(ty.Gains, g)
+			ty.Total.AddEqual(&g.Gain)
+		}
+	}
+
+	list := make([]*TaxYear, 0, len(years))
+	for _, ty := range years {
+		sort.SliceStable(ty.Gains, func(i, j int) bool {
+			return ty.Gains[i].Disposed.Before(ty.Gains[j].Disposed)
+		})
+		list = append(list, ty)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Year < list[j].Year })
+
+	return list
+}
+
+// lotGain computes the realized gain of a closed lot.
+func lotGain(lot *model.Lot) *RealizedGain {
+	g := RealizedGain{Account: lot.Account, Lot: lot}
+
+	for _, s := range lot.Splits {
+		date := time.Time(s.Transaction.DatePosted)
+
+		switch {
+		case s.Quantity.IsZero():
+			// gain split written by the lot scrubber
+			g.RecordedGain.AddEqual(&s.Value)
+		case s.Quantity.Sign() > 0:
+			// acquisition
+			g.Quantity.AddEqual(&s.Quantity)
+			g.CostBasis.AddEqual(&s.Value)
+			if g.Acquired.IsZero() || date.Before(g.Acquired) {
+				g.Acquired = date
+			}
+		default:
+			// disposal
+			g.Proceeds.SubEqual(&s.Value)
+			if date.After(g.Disposed) {
+				g.Disposed = date
+			}
+		}
+	}
+	g.Gain.Copy(types.Sub(&g.Proceeds, &g.CostBasis))
+
+	return &g
+}
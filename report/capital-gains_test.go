@@ -0,0 +1,109 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+func num(s string) types.Numeric {
+	n, err := types.FromString(s)
+	if err != nil {
+		panic(err)
+	}
+	return *n
+}
+
+func date(s string) types.Timespec {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return types.Timespec(t)
+}
+
+// addLotSplit adds to lot a new split posted on the given date.
+func addLotSplit(lot *model.Lot, posted, quantity, value string) {
+	trn := &model.Transaction{DatePosted: date(posted)}
+	s := &model.Split{
+		Account:     lot.Account,
+		Lot:         lot,
+		Transaction: trn,
+		Quantity:    num(quantity),
+		Value:       num(value),
+	}
+	trn.Splits.Add(s)
+	lot.Splits.Add(s)
+}
+
+func TestCapitalGains(t *testing.T) {
+	acme := &model.Account{Name: "ACME", Type: types.AccountTypeStock}
+	cash := &model.Account{Name: "Cash", Type: types.AccountTypeCash}
+
+	lot1 := &model.Lot{Account: acme}
+	addLotSplit(lot1, "2014-01-10", "10", "100000/100")
+	addLotSplit(lot1, "2016-06-15", "-10", "-150000/100")
+	addLotSplit(lot1, "2016-06-15", "0", "50000/100")
+
+	lot2 := &model.Lot{Account: acme}
+	addLotSplit(lot2, "2016-04-10", "5", "60000/100")
+	addLotSplit(lot2, "2016-05-01", "-3", "-30000/100")
+	addLotSplit(lot2, "2017-02-01", "-2", "-20000/100")
+
+	// open lot: not reported
+	lot3 := &model.Lot{Account: acme}
+	addLotSplit(lot3, "2016-01-10", "5", "60000/100")
+
+	// not a stock account: not reported
+	lot4 := &model.Lot{Account: cash}
+	addLotSplit(lot4, "2016-01-10", "5", "5")
+	addLotSplit(lot4, "2016-01-11", "-5", "-6")
+
+	acme.Lots = model.Lots{lot1, lot2, lot3}
+	cash.Lots = model.Lots{lot4}
+
+	book := &model.Book{}
+	book.Accounts.Add(acme)
+	book.Accounts.Add(cash)
+
+	years := CapitalGains(book)
+	if len(years) != 2 {
+		t.Fatalf("CapitalGains: expected 2 tax years, got %d", len(years))
+	}
+
+	var testCases = []struct {
+		year       int
+		lot        *model.Lot
+		cost, gain float64
+		recorded   float64
+		longTerm   bool
+	}{
+		{2016, lot1, 1000, 500, 500, true},
+		{2017, lot2, 600, -100, 0, false},
+	}
+	for j, tc := range testCases {
+		ty := years[j]
+		if ty.Year != tc.year || len(ty.Gains) != 1 {
+			t.Errorf("TaxYear %d: expected year %d with 1 gain, got %d gains", ty.Year, tc.year, len(ty.Gains))
+			continue
+		}
+		g := ty.Gains[0]
+		if g.Lot != tc.lot {
+			t.Errorf("TaxYear %d: unexpected lot", ty.Year)
+		}
+		if g.CostBasis.Float64() != tc.cost {
+			t.Errorf("TaxYear %d: CostBasis expected %v, got %v", ty.Year, tc.cost, g.CostBasis.Float64())
+		}
+		if g.Gain.Float64() != tc.gain || ty.Total.Float64() != tc.gain {
+			t.Errorf("TaxYear %d: Gain expected %v, got %v", ty.Year, tc.gain, g.Gain.Float64())
+		}
+		if g.RecordedGain.Float64() != tc.recorded {
+			t.Errorf("TaxYear %d: RecordedGain expected %v, got %v", ty.Year, tc.recorded, g.RecordedGain.Float64())
+		}
+		if g.LongTerm() != tc.longTerm {
+			t.Errorf("TaxYear %d: LongTerm expected %v", ty.Year, tc.longTerm)
+		}
+	}
+}
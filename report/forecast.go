@@ -0,0 +1,176 @@
+package report
+
+import (
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// Forecast is the projection of the balance of every account, day by day,
+// obtained instantiating the enabled scheduled transactions of the book
+// on their future occurrences.
+type Forecast struct {
+	// From is the first day of the forecast.
+	From time.Time
+	// Days is the number of days of the forecast.
+	Days int
+	// Balances maps every account to its balance at the end of each day.
+	Balances map[*model.Account][]types.Numeric
+	// Threshold is the balance under which a bank account is flagged,
+	// and, without CreditLimit, the debt over which a credit account is.
+	Threshold types.Numeric
+	// CreditLimit is the debt over which a credit account is flagged.
+	// The balance of a credit account is negative when it has a debt,
+	// so it is compared inverted. If nil, Threshold is used.
+	CreditLimit *types.Numeric
+	// Crossings maps the bank and credit accounts whose balance goes
+	// under Threshold, or whose debt goes over CreditLimit, to the first
+	// day it happens.
+	Crossings map[*model.Account]time.Time
+}
+
+// NewForecast returns the forecast of the book for the given number of
+// months starting from the date of from. The transactions already in the
+// book are applied on their posted date. The overdue instances of the
+// scheduled transactions, before from but not yet created by "since last
+// run", are applied on the first day. Template amounts are assumed to
+// be expressed in the commodity of their account.
+func NewForecast(book *model.Book, from time.Time, months int, threshold types.Numeric, creditLimit *types.Numeric) *Forecast {
+	from = dateOf(from)
+	to := from.AddDate(0, months, 0)
+	f := &Forecast{
+		From:        from,
+		Days:        int(to.Sub(from).Hours()/24) + 1,
+		Balances:    map[*model.Account][]types.Numeric{},
+		Threshold:   threshold,
+		CreditLimit: creditLimit,
+		Crossings:   map[*model.Account]time.Time{},
+	}
+
+	// changes[acc][j] is the change of the balance of acc in day j;
+	// index 0 includes the balance before from.
+	changes := map[*model.Account][]types.Numeric{}
+	add := func(acc *model.Account, day int, amount *types.Numeric) {
+		if acc == nil || day >= f.Days {
+			return
+		}
+		if day < 0 {
+			day = 0
+		}
+		c, ok := changes[acc]
+		if !ok {
+			c = make([]types.Numeric, f.Days)
+			changes[acc] = c
+		}
+		c[day].AddEqual(amount)
+	}
+
+	for _, t := range book.Transactions {
+		day := f.dayOf(time.Time(t.DatePosted))
+		for _, s := range t.Splits {
+			add(s.Account, day, &s.Quantity)
+		}
+	}
+
+	for _, sx := range book.ScheduledTransactions {
+		if !sx.Enabled {
+			continue
+		}
+		// the zero time includes the overdue instances, with a negative day
+		for _, d := range sx.Occurrences(time.Time{}, to) {
+			day := f.dayOf(d)
+			for _, tt := range sx.Templates {
+				for _, ts := range tt.Splits {
+					add(ts.Account, day, ts.Amount())
+				}
+			}
+		}
+	}
+
+	for _, acc := range book.Accounts.List {
+		c, ok := changes[acc]
+		if !ok {
+			c = make([]types.Numeric, f.Days)
+		}
+		balances := make([]types.Numeric, f.Days)
+		var balance types.Numeric
+		for j := range c {
+			balance.AddEqual(&c[j])
+			balances[j].Copy(&balance)
+			if _, crossed := f.Crossings[acc]; !crossed && f.crosses(acc, &balance) {
+				f.Crossings[acc] = f.Date(j)
+			}
+		}
+		f.Balances[acc] = balances
+	}
+
+	return f
+}
+
+// Date returns the date of the j-th day of the forecast.
+func (f *Forecast) Date(j int) time.Time {
+	return f.From.AddDate(0, 0, j)
+}
+
+// Balance returns the balance of the account at the end of the given day.
+// It returns nil if the date is outside the forecast.
+func (f *Forecast) Balance(acc *model.Account, date time.Time) *types.Numeric {
+	j := f.dayOf(date)
+	balances, ok := f.Balances[acc]
+	if !ok || j < 0 || j >= f.Days {
+		return nil
+	}
+	return types.Copy(&balances[j])
+}
+
+// dayOf returns the index of the day of date:
+// negative if before From.
+func (f *Forecast) dayOf(date time.Time) int {
+	return int(dateOf(date).Sub(f.From).Hours() / 24)
+}
+
+// crosses returns true if the balance of the account is under the
+// threshold, for a bank account, or its debt is over the credit limit,
+// or the threshold if there is no limit, for a credit account.
+func (f *Forecast) crosses(acc *model.Account, balance *types.Numeric) bool {
+	switch {
+	case isCredit(acc):
+		limit := f.CreditLimit
+		if limit == nil {
+			limit = &f.Threshold
+		}
+		return types.Add(balance, limit).Sign() < 0
+	case isBankOrCredit(acc):
+		return types.Sub(balance, &f.Threshold).Sign() < 0
+	}
+	return false
+}
+
+// isBankOrCredit returns true for the accounts of type bank or credit.
+func isBankOrCredit(acc *model.Account) bool {
+	switch acc.Type {
+	case types.AccountTypeBank, types.AccountTypeChecking, types.AccountTypeSavings,
+		types.AccountTypeMoneyMrkt:
+		return true
+	}
+	return isCredit(acc)
+}
+
+// isCredit returns true for the accounts of type credit,
+// whose balance is negative when there is a debt.
+func isCredit(acc *model.Account) bool {
+	return acc.Type == types.AccountTypeCredit || acc.Type == types.AccountTypeCreditLine
+}
+
+// IsWatched returns true if the account is checked against the threshold
+// or the credit limit, i.e. it is a bank or credit account.
+func (f *Forecast) IsWatched(acc *model.Account) bool {
+	return isBankOrCredit(acc)
+}
+
+// dateOf returns the date of t at midnight UTC.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
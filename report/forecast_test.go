@@ -0,0 +1,178 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+func TestForecast(t *testing.T) {
+	bank := &model.Account{Name: "Bank", Type: types.AccountTypeBank}
+	rent := &model.Account{Name: "Rent", Type: types.AccountTypeExpense}
+	equity := &model.Account{Name: "Opening", Type: types.AccountTypeEquity}
+
+	book := &model.Book{}
+	book.Accounts.Add(bank)
+	book.Accounts.Add(rent)
+	book.Accounts.Add(equity)
+
+	// opening balance
+	opening := &model.Transaction{DatePosted: date("2016-01-01")}
+	opening.Splits.Add(&model.Split{Account: bank, Quantity: num("2000")})
+	opening.Splits.Add(&model.Split{Account: equity, Quantity: num("-2000")})
+	book.Transactions.Add(opening)
+
+	// rent of 750 on the 5th of every month, 5 occurrences left
+	sx := &model.ScheduledTransaction{
+		Name:     "Rent",
+		Enabled:  true,
+		Start:    types.GDate(date("2016-01-05")),
+		Last:     types.GDate(date("2016-01-05")),
+		NumOccur: 6,
+		RemOccur: 5,
+		Schedule: types.Recurrences{
+			&types.Recurrence{Mult: 1, PeriodType: types.PeriodTypeMonth, Start: types.GDate(date("2016-01-05"))},
+		},
+		Templates: []*model.TemplateTransaction{
+			{Splits: []*model.TemplateSplit{
+				{Account: rent, Debit: num("750")},
+				{Account: bank, Credit: num("750")},
+			}},
+		},
+	}
+	book.ScheduledTransactions.Add(sx)
+
+	// disabled: ignored
+	book.ScheduledTransactions.Add(&model.ScheduledTransaction{
+		Name:     "Disabled",
+		Start:    types.GDate(date("2016-01-01")),
+		Schedule: sx.Schedule,
+		Templates: []*model.TemplateTransaction{
+			{Splits: []*model.TemplateSplit{{Account: bank, Credit: num("100000")}}},
+		},
+	})
+
+	f := NewForecast(book, time.Time(date("2016-01-20")), 12, num("0"), nil)
+
+	var testCases = []struct {
+		acc      *model.Account
+		date     string
+		expected string
+	}{
+		{bank, "2016-01-20", "2000"},
+		{bank, "2016-02-04", "2000"},
+		{bank, "2016-02-05", "1250"},
+		{bank, "2016-03-07", "500"},
+		{bank, "2016-04-05", "-250"},
+		{rent, "2016-04-05", "2250"},
+		{bank, "2016-07-05", "-1750"},
+		{bank, "2016-08-05", "-1750"},
+	}
+	for _, tc := range testCases {
+		b := f.Balance(tc.acc, time.Time(date(tc.date)))
+		if b == nil || b.String() != tc.expected {
+			t.Errorf("Balance(%s, %s): expected %s, got %v", tc.acc.Name, tc.date, tc.expected, b)
+		}
+	}
+
+	if d, ok := f.Crossings[bank]; !ok || d.Format("2006-01-02") != "2016-04-05" {
+		t.Errorf("Crossings[Bank]: expected 2016-04-05, got %v", d)
+	}
+	if _, ok := f.Crossings[rent]; ok {
+		t.Errorf("Crossings[Rent]: expense accounts must not be flagged")
+	}
+}
+
+func TestForecastCredit(t *testing.T) {
+	card := &model.Account{Name: "Card", Type: types.AccountTypeCredit}
+	shop := &model.Account{Name: "Shop", Type: types.AccountTypeExpense}
+
+	book := &model.Book{}
+	book.Accounts.Add(card)
+	book.Accounts.Add(shop)
+
+	// debt of 300
+	opening := &model.Transaction{DatePosted: date("2022-12-15")}
+	opening.Splits.Add(&model.Split{Account: card, Quantity: num("-300")})
+	opening.Splits.Add(&model.Split{Account: shop, Quantity: num("300")})
+	book.Transactions.Add(opening)
+
+	// 100 at the end of every month, moved back from the weekend
+	book.ScheduledTransactions.Add(&model.ScheduledTransaction{
+		Name:    "Subscription",
+		Enabled: true,
+		Start:   types.GDate(date("2023-01-31")),
+		Schedule: types.Recurrences{
+			&types.Recurrence{Mult: 1, PeriodType: types.PeriodTypeMonth, Start: types.GDate(date("2023-01-31")),
+				WeekendAdjust: types.WeekendAdjustBack},
+		},
+		Templates: []*model.TemplateTransaction{
+			{Splits: []*model.TemplateSplit{
+				{Account: shop, Debit: num("100")},
+				{Account: card, Credit: num("100")},
+			}},
+		},
+	})
+
+	// no credit limit: a debt of 500 is within the threshold, 600 is over it
+	f := NewForecast(book, time.Time(date("2023-01-01")), 12, num("500"), nil)
+	if d, ok := f.Crossings[card]; !ok || d.Format("2006-01-02") != "2023-03-31" {
+		t.Errorf("Crossings[Card]: expected 2023-03-31, got %v", d)
+	}
+	// all the 12 month end occurrences
+	if b := f.Balance(card, time.Time(date("2023-12-31"))); b == nil || b.String() != "-1500" {
+		t.Errorf("Balance(Card, 2023-12-31): expected -1500, got %v", b)
+	}
+
+	// a debt of 1000 is within the limit, 1100 is over it:
+	// the threshold is not used
+	limit := num("1000")
+	f = NewForecast(book, time.Time(date("2023-01-01")), 12, num("0"), &limit)
+	if d, ok := f.Crossings[card]; !ok || d.Format("2006-01-02") != "2023-08-31" {
+		t.Errorf("Crossings[Card]: expected 2023-08-31, got %v", d)
+	}
+}
+
+func TestForecastOverdue(t *testing.T) {
+	bank := &model.Account{Name: "Bank", Type: types.AccountTypeBank}
+	rent := &model.Account{Name: "Rent", Type: types.AccountTypeExpense}
+
+	book := &model.Book{}
+	book.Accounts.Add(bank)
+	book.Accounts.Add(rent)
+
+	opening := &model.Transaction{DatePosted: date("2016-01-01")}
+	opening.Splits.Add(&model.Split{Account: bank, Quantity: num("1000")})
+	book.Transactions.Add(opening)
+
+	// rent of 750 on the 5th of every month, last created on 2016-01-05:
+	// the instances of 2016-02-05 and 2016-03-05 are overdue
+	book.ScheduledTransactions.Add(&model.ScheduledTransaction{
+		Name:    "Rent",
+		Enabled: true,
+		Start:   types.GDate(date("2016-01-05")),
+		Last:    types.GDate(date("2016-01-05")),
+		Schedule: types.Recurrences{
+			&types.Recurrence{Mult: 1, PeriodType: types.PeriodTypeMonth, Start: types.GDate(date("2016-01-05"))},
+		},
+		Templates: []*model.TemplateTransaction{
+			{Splits: []*model.TemplateSplit{
+				{Account: rent, Debit: num("750")},
+				{Account: bank, Credit: num("750")},
+			}},
+		},
+	})
+
+	f := NewForecast(book, time.Time(date("2016-03-10")), 1, num("0"), nil)
+	if b := f.Balance(bank, time.Time(date("2016-03-10"))); b == nil || b.String() != "-500" {
+		t.Errorf("Balance(Bank, 2016-03-10): expected -500, got %v", b)
+	}
+	if b := f.Balance(bank, time.Time(date("2016-04-05"))); b == nil || b.String() != "-1250" {
+		t.Errorf("Balance(Bank, 2016-04-05): expected -1250, got %v", b)
+	}
+	if d, ok := f.Crossings[bank]; !ok || d.Format("2006-01-02") != "2016-03-10" {
+		t.Errorf("Crossings[Bank]: expected 2016-03-10, got %v", d)
+	}
+}
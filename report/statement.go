@@ -0,0 +1,85 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+	"github.com/mmbros/gnucash-viewer/types"
+)
+
+// StatementLine is a movement of the account of a customer:
+// an invoice or a payment.
+type StatementLine struct {
+	Date time.Time
+	// Invoice is the invoice posted, nil for payments.
+	Invoice     *model.Invoice
+	Description string
+	// Amount is positive for invoices and negative for payments.
+	Amount types.Numeric
+	// Balance is the balance after the movement.
+	Balance types.Numeric
+}
+
+// Statement is the statement of account of a customer for a date range.
+type Statement struct {
+	Customer *model.Customer
+	From, To time.Time
+	Opening  types.Numeric
+	Lines    []*StatementLine
+	Closing  types.Numeric
+}
+
+// NewStatement returns the statement of the customer from date from to
+// date to, both included. It considers the splits of the business lots
+// of the customer (and of its jobs) in the accounts receivable.
+func NewStatement(book *model.Book, cust *model.Customer, from, to time.Time) *Statement {
+	from, to = dateOf(from), dateOf(to)
+	st := &Statement{Customer: cust, From: from, To: to}
+	biz := &book.Business
+
+	for _, acc := range book.Accounts.List {
+		if acc.Type != types.AccountTypeReceivable {
+			continue
+		}
+		for _, lot := range acc.Lots {
+			if owner := biz.LotOwner(lot); owner == nil || owner.Customer != cust {
+				continue
+			}
+			inv := biz.LotInvoice(lot)
+			for _, s := range lot.Splits {
+				date := dateOf(time.Time(s.Transaction.DatePosted))
+				switch {
+				case date.Before(from):
+					st.Opening.AddEqual(&s.Quantity)
+				case !date.After(to):
+					line := &StatementLine{
+						Date:        date,
+						Description: s.Transaction.Description,
+					}
+					line.Amount.Copy(&s.Quantity)
+					if inv != nil && inv.PostTxn == s.Transaction {
+						line.Invoice = inv
+					}
+					st.Lines = append(st.Lines, line)
+				}
+			}
+		}
+	}
+
+	// invoices before payments in the same day
+	sort.SliceStable(st.Lines, func(i, j int) bool {
+		li, lj := st.Lines[i], st.Lines[j]
+		if !li.Date.Equal(lj.Date) {
+			return li.Date.Before(lj.Date)
+		}
+		return li.Invoice != nil && lj.Invoice == nil
+	})
+
+	st.Closing.Copy(&st.Opening)
+	for _, line := range st.Lines {
+		st.Closing.AddEqual(&line.Amount)
+		line.Balance.Copy(&st.Closing)
+	}
+	return st
+}
@@ -0,0 +1,207 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmbros/gnucash-viewer/model"
+)
+
+const testStatementBook = `<?xml version="1.0" encoding="utf-8" ?>
+<gnc-v2
+     xmlns:gnc="http://www.gnucash.org/XML/gnc"
+     xmlns:act="http://www.gnucash.org/XML/act"
+     xmlns:book="http://www.gnucash.org/XML/book"
+     xmlns:cmdty="http://www.gnucash.org/XML/cmdty"
+     xmlns:slot="http://www.gnucash.org/XML/slot"
+     xmlns:split="http://www.gnucash.org/XML/split"
+     xmlns:trn="http://www.gnucash.org/XML/trn"
+     xmlns:ts="http://www.gnucash.org/XML/ts"
+     xmlns:lot="http://www.gnucash.org/XML/lot"
+     xmlns:addr="http://www.gnucash.org/XML/addr"
+     xmlns:owner="http://www.gnucash.org/XML/owner"
+     xmlns:cust="http://www.gnucash.org/XML/cust"
+     xmlns:invoice="http://www.gnucash.org/XML/invoice">
+<gnc:book version="2.0.0">
+<book:id type="guid">00000000000000000000000000000001</book:id>
+<gnc:account version="2.0.0">
+  <act:name>Root Account</act:name>
+  <act:id type="guid">000000000000000000000000000000c0</act:id>
+  <act:type>ROOT</act:type>
+</gnc:account>
+<gnc:account version="2.0.0">
+  <act:name>Receivable</act:name>
+  <act:id type="guid">000000000000000000000000000000c1</act:id>
+  <act:type>RECEIVABLE</act:type>
+  <act:commodity><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></act:commodity>
+  <act:parent type="guid">000000000000000000000000000000c0</act:parent>
+  <act:lots>
+    <gnc:lot version="2.0.0">
+      <lot:id type="guid">000000000000000000000000000000f1</lot:id>
+    </gnc:lot>
+    <gnc:lot version="2.0.0">
+      <lot:id type="guid">000000000000000000000000000000f2</lot:id>
+    </gnc:lot>
+    <gnc:lot version="2.0.0">
+      <lot:id type="guid">000000000000000000000000000000f3</lot:id>
+      <lot:slots>
+        <slot>
+          <slot:key>gncOwner</slot:key>
+          <slot:value type="frame">
+            <slot>
+              <slot:key>owner-type</slot:key>
+              <slot:value type="integer">2</slot:value>
+            </slot>
+            <slot>
+              <slot:key>owner-guid</slot:key>
+              <slot:value type="guid">000000000000000000000000000000a1</slot:value>
+            </slot>
+          </slot:value>
+        </slot>
+      </lot:slots>
+    </gnc:lot>
+  </act:lots>
+</gnc:account>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d1</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-02-10 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:description>Invoice 1</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e1</split:id>
+      <split:value>10000/100</split:value>
+      <split:quantity>10000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+      <split:lot type="guid">000000000000000000000000000000f1</split:lot>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d2</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-03-05 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:description>Invoice 2</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e2</split:id>
+      <split:value>5000/100</split:value>
+      <split:quantity>5000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+      <split:lot type="guid">000000000000000000000000000000f2</split:lot>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:transaction version="2.0.0">
+  <trn:id type="guid">000000000000000000000000000000d3</trn:id>
+  <trn:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></trn:currency>
+  <trn:date-posted><ts:date>2016-03-15 00:00:00 +0100</ts:date></trn:date-posted>
+  <trn:description>Bank transfer</trn:description>
+  <trn:splits>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e3</split:id>
+      <split:value>-10000/100</split:value>
+      <split:quantity>-10000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+      <split:lot type="guid">000000000000000000000000000000f1</split:lot>
+    </trn:split>
+    <trn:split>
+      <split:id type="guid">000000000000000000000000000000e4</split:id>
+      <split:value>-2000/100</split:value>
+      <split:quantity>-2000/100</split:quantity>
+      <split:account type="guid">000000000000000000000000000000c1</split:account>
+      <split:lot type="guid">000000000000000000000000000000f3</split:lot>
+    </trn:split>
+  </trn:splits>
+</gnc:transaction>
+<gnc:GncCustomer version="2.0.0">
+  <cust:guid type="guid">000000000000000000000000000000a1</cust:guid>
+  <cust:name>Acme Srl</cust:name>
+  <cust:id>000001</cust:id>
+  <cust:addr version="2.0.0"/>
+  <cust:shipaddr version="2.0.0"/>
+  <cust:taxincluded>NO</cust:taxincluded>
+  <cust:active>1</cust:active>
+  <cust:discount>0/1</cust:discount>
+  <cust:credit>0/1</cust:credit>
+  <cust:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></cust:currency>
+  <cust:use-tt>0</cust:use-tt>
+</gnc:GncCustomer>
+<gnc:GncInvoice version="2.0.0">
+  <invoice:guid type="guid">000000000000000000000000000000b1</invoice:guid>
+  <invoice:id>000001</invoice:id>
+  <invoice:owner version="2.0.0">
+    <owner:type>gncCustomer</owner:type>
+    <owner:id type="guid">000000000000000000000000000000a1</owner:id>
+  </invoice:owner>
+  <invoice:active>1</invoice:active>
+  <invoice:posttxn type="guid">000000000000000000000000000000d1</invoice:posttxn>
+  <invoice:postlot type="guid">000000000000000000000000000000f1</invoice:postlot>
+  <invoice:postacc type="guid">000000000000000000000000000000c1</invoice:postacc>
+  <invoice:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></invoice:currency>
+</gnc:GncInvoice>
+<gnc:GncInvoice version="2.0.0">
+  <invoice:guid type="guid">000000000000000000000000000000b2</invoice:guid>
+  <invoice:id>000002</invoice:id>
+  <invoice:owner version="2.0.0">
+    <owner:type>gncCustomer</owner:type>
+    <owner:id type="guid">000000000000000000000000000000a1</owner:id>
+  </invoice:owner>
+  <invoice:active>1</invoice:active>
+  <invoice:posttxn type="guid">000000000000000000000000000000d2</invoice:posttxn>
+  <invoice:postlot type="guid">000000000000000000000000000000f2</invoice:postlot>
+  <invoice:postacc type="guid">000000000000000000000000000000c1</invoice:postacc>
+  <invoice:currency><cmdty:space>ISO4217</cmdty:space><cmdty:id>EUR</cmdty:id></invoice:currency>
+</gnc:GncInvoice>
+</gnc:book>
+</gnc-v2>
+`
+
+func TestStatement(t *testing.T) {
+	var gnc model.Gnc
+	if err := xml.NewDecoder(strings.NewReader(testStatementBook)).Decode(&gnc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	book := gnc.Book
+	cust := book.Business.Customer("000000000000000000000000000000a1")
+
+	from := time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC)
+	st := NewStatement(book, cust, from, to)
+
+	if actual := st.Opening.Float64(); actual != 100 {
+		t.Errorf("Statement.Opening: expected 100, got %v", actual)
+	}
+	if actual := st.Closing.Float64(); actual != 30 {
+		t.Errorf("Statement.Closing: expected 30, got %v", actual)
+	}
+
+	var testCases = []struct {
+		date    string
+		invoice string
+		amount  float64
+		balance float64
+	}{
+		{"2016-03-05", "000002", 50, 150},
+		{"2016-03-15", "", -100, 50},
+		{"2016-03-15", "", -20, 30},
+	}
+	if len(st.Lines) != len(testCases) {
+		t.Fatalf("Statement.Lines: expected %d lines, got %d", len(testCases), len(st.Lines))
+	}
+	for j, tc := range testCases {
+		line := st.Lines[j]
+		var invoice string
+		if line.Invoice != nil {
+			invoice = line.Invoice.Number
+		}
+		if d := line.Date.Format("2006-01-02"); d != tc.date || invoice != tc.invoice ||
+			line.Amount.Float64() != tc.amount || line.Balance.Float64() != tc.balance {
+			t.Errorf("Statement.Lines[%d]: expected {%s %q %v %v}, got {%s %q %v %v}", j,
+				tc.date, tc.invoice, tc.amount, tc.balance,
+				d, invoice, line.Amount.Float64(), line.Balance.Float64())
+		}
+	}
+}
@@ -67,10 +67,28 @@ func AccountTypeFromString(v string) AccountType {
 	return AccountTypeNone
 }
 
+// gncNames are the names of the account types used by the GnuCash files,
+// in the order of the AccountType constants.
+var gncNames = []string{
+	"NONE", "BANK", "CASH", "CREDIT", "ASSET", "LIABILITY", "STOCK", "MUTUAL",
+	"CURRENCY", "INCOME", "EXPENSE", "EQUITY", "RECEIVABLE", "PAYABLE", "ROOT",
+	"TRADING", "CHECKING", "SAVINGS", "MONEYMRKT", "CREDITLINE",
+}
+
+// GncString returns the name of the account type used by the GnuCash files.
+func (at AccountType) GncString() string {
+	if at < 0 || int(at) >= len(gncNames) {
+		return "NONE"
+	}
+	return gncNames[at]
+}
+
 // UnmarshalXML implements xml.Unmarshaler interface
 func (at *AccountType) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	var v string
-	d.DecodeElement(&v, &start)
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
 	*at = AccountTypeFromString(v)
 	return nil
 }
@@ -11,3 +11,11 @@ func TestAccountType_String(t *testing.T) {
 
 	}
 }
+
+func TestAccountType_GncString(t *testing.T) {
+	for _, name := range gncNames {
+		if actual := AccountTypeFromString(name).GncString(); actual != name {
+			t.Errorf("GncString: expected %q, got %q", name, actual)
+		}
+	}
+}
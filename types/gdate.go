@@ -0,0 +1,44 @@
+package types
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+/*
+GDate = element gdate { xsd:string { pattern = "[0-9]{4}-[0-9]{2}-[0-9]{2}" } }
+*/
+
+// GDate represent a gnucash GDate value, a date without time.
+type GDate time.Time
+
+// gdateForm is the layout of a GDate value.
+const gdateForm = "2006-01-02"
+
+// UnmarshalXML implements xml.Unmarshaler interface
+func (gd *GDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Date string `xml:"gdate"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	x, err := time.Parse(gdateForm, v.Date)
+	if err != nil {
+		return err
+	}
+	*gd = GDate(x)
+
+	return nil
+}
+
+// String returns the date formatted as "2006-01-02".
+// Return "" in case of zero GDate.
+func (gd GDate) String() string {
+	t := time.Time(gd)
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(gdateForm)
+}
@@ -3,13 +3,20 @@ package types
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"strconv"
 	"strings"
 )
 
 // Numeric type is used to represents a GnuCash numeric type.
 // Numeric{} equals 0 numeric number.
+//
+// The arithmetic is exact: a result whose numerator or denominator
+// overflows numint is reduced and, if still too large, is kept as
+// a big.Rat.
 type Numeric struct {
 	// Numerator
 	num numint
@@ -18,10 +25,17 @@ type Numeric struct {
 	// if den == 0 then the Numeric is 0
 	// den is always >= 0
 	den numint
+
+	// big, if not nil, is the value of a Numeric not fitting num and den,
+	// that are then 0. It is never changed once set.
+	big *big.Rat
 }
 
 // String returns the string representation of Numeric value.
 func (n Numeric) String() string {
+	if n.big != nil {
+		return n.big.RatString()
+	}
 	switch n.den {
 	case 0: // den == 0
 		return "0"
@@ -35,6 +49,18 @@ func (n Numeric) String() string {
 	}
 }
 
+// GncString returns the "num/den" representation of Numeric value
+// used by the GnuCash files. The zero value is returned as "0/1".
+func (n Numeric) GncString() string {
+	if n.big != nil {
+		return n.big.String()
+	}
+	if n.den == 0 {
+		return "0/1"
+	}
+	return fmt.Sprintf("%d/%d", n.num, n.den)
+}
+
 // New creates a new numeric with numerator num and denominator den.
 func New(num, den numint) *Numeric {
 	if den < 0 {
@@ -43,18 +69,57 @@ func New(num, den numint) *Numeric {
 	return &Numeric{num: num, den: den}
 }
 
+// FromInt64 creates a new numeric with numerator num and denominator den,
+// as stored by the SQL backends.
+func FromInt64(num, den int64) *Numeric {
+	return New(numint(num), numint(den))
+}
+
 // Copy returns a new Numeric equals to x.
 func Copy(x *Numeric) *Numeric {
-	return &Numeric{x.num, x.den}
+	return &Numeric{x.num, x.den, x.big}
 }
 
 // Copy sets the existing Numeric n to the value of Numeric x.
 func (n *Numeric) Copy(x *Numeric) {
-	n.num, n.den = x.num, x.den
+	n.num, n.den, n.big = x.num, x.den, x.big
+}
+
+// rat returns the value of n as a big.Rat, that must not be changed.
+func (n *Numeric) rat() *big.Rat {
+	switch {
+	case n.big != nil:
+		return n.big
+	case n.IsZero():
+		return new(big.Rat)
+	}
+	return big.NewRat(int64(n.num), int64(n.den))
+}
+
+// setRat sets n to the value of r, as num and den if they fit numint.
+func (n *Numeric) setRat(r *big.Rat) {
+	if r.Num().IsInt64() && r.Denom().IsInt64() {
+		*n = Numeric{num: numint(r.Num().Int64()), den: numint(r.Denom().Int64())}
+		return
+	}
+	*n = Numeric{big: r}
 }
 
 // FromString creates a new Numeric from string.
+// The values not fitting numint are accepted, as written by GncString.
 func FromString(v string) (*Numeric, error) {
+	n, err := fromString(v)
+	if errors.Is(err, strconv.ErrRange) && !strings.ContainsAny(v, ".eE") {
+		if r, ok := new(big.Rat).SetString(v); ok {
+			n = &Numeric{}
+			n.setRat(r)
+			return n, nil
+		}
+	}
+	return n, err
+}
+
+func fromString(v string) (*Numeric, error) {
 	var n Numeric
 
 	idx := strings.IndexByte(v, '/')
@@ -88,19 +153,62 @@ func FromString(v string) (*Numeric, error) {
 // NOTE: Numeric{1, 0} == Numeric{10, 0} == Numeric{0, 0} == Numeric{0, 1}.
 func (n *Numeric) IsZero() bool {
 	// must be consistent con Sign func
+	if n.big != nil {
+		return n.big.Sign() == 0
+	}
 	return (n.num == 0) || (n.den == 0)
 }
 
-// Equals returns true if z == x.
+// Equals returns true if z == x, with the same numerator and denominator.
 //
-// NOTE: Numeric{1, 1} != Numeric{10, 10}.
+// NOTE: Numeric{1, 1} != Numeric{10, 10}. See Equal.
 func (n *Numeric) Equals(x *Numeric) bool {
 	if n.IsZero() {
 		return x.IsZero()
 	}
+	if n.big != nil || x.big != nil {
+		return n.big != nil && x.big != nil && n.big.Cmp(x.big) == 0
+	}
 	return (n.num == x.num) && (n.den == x.den)
 }
 
+// Equal returns true if z and x have the same value.
+//
+// NOTE: Numeric{1, 1} == Numeric{10, 10}. See Equals.
+func (n *Numeric) Equal(x *Numeric) bool {
+	return n.Cmp(x) == 0
+}
+
+// Cmp compares z and x, returning:
+//
+//	-1 if z <  x
+//	 0 if z == x
+//	+1 if z >  x
+//
+func (n *Numeric) Cmp(x *Numeric) int {
+	if n.big == nil && x.big == nil {
+		switch {
+		case n.IsZero():
+			return -x.Sign()
+		case x.IsZero():
+			return n.Sign()
+		}
+		// a/b < c/d <=> a*d < c*b, being b, d > 0
+		ad, ok1 := mul(n.num, x.den)
+		cb, ok2 := mul(x.num, n.den)
+		if ok1 && ok2 {
+			switch {
+			case ad < cb:
+				return -1
+			case ad > cb:
+				return 1
+			}
+			return 0
+		}
+	}
+	return n.rat().Cmp(x.rat())
+}
+
 // Sign returns:
 //
 //	-1 if z <  0
@@ -108,7 +216,10 @@ func (n *Numeric) Equals(x *Numeric) bool {
 //	+1 if z >  0
 //
 func (n *Numeric) Sign() int {
-	if n.den == 0 || n.den == 0 {
+	if n.big != nil {
+		return n.big.Sign()
+	}
+	if n.num == 0 || n.den == 0 {
 		// must be consistent con IsZero func
 		return 0
 	}
@@ -121,6 +232,10 @@ func (n *Numeric) Sign() int {
 
 // NegEqual sets z to -z
 func (n *Numeric) NegEqual() {
+	if n.big != nil || n.num == math.MinInt64 {
+		n.setRat(new(big.Rat).Neg(n.rat()))
+		return
+	}
 	n.num = -n.num
 }
 
@@ -136,13 +251,25 @@ func (n *Numeric) AddEqual(x *Numeric) {
 		n.Copy(x)
 		return
 	}
-	if n.den == x.den {
-		n.num += x.num
-		return
+	if n.big == nil && x.big == nil {
+		if n.den == x.den {
+			if num, ok := add(n.num, x.num); ok {
+				n.num = num
+				return
+			}
+		} else {
+			// lcm of the denominators
+			g, ok1 := mul(n.den/gcd(n.den, x.den), x.den)
+			a, ok2 := mul(n.num, g/n.den)
+			b, ok3 := mul(x.num, g/x.den)
+			num, ok4 := add(a, b)
+			if ok1 && ok2 && ok3 && ok4 {
+				n.num, n.den = num, g
+				return
+			}
+		}
 	}
-	g := lcm(n.den, x.den)
-	n.num = n.num*(g/n.den) + x.num*(g/x.den)
-	n.den = g
+	n.setRat(new(big.Rat).Add(n.rat(), x.rat()))
 }
 
 // SubEqual function: z.SubEqual(x) -> z -= x
@@ -165,9 +292,92 @@ func Sub(x *Numeric, y *Numeric) *Numeric {
 	return z
 }
 
-// Neg function sets x to -x.
+// Neg function returns -x.
 func Neg(x *Numeric) *Numeric {
-	return &Numeric{num: -x.num, den: x.den}
+	z := Copy(x)
+	z.NegEqual()
+	return z
+}
+
+// Abs function returns |x|.
+func Abs(x *Numeric) *Numeric {
+	z := Copy(x)
+	if z.Sign() < 0 {
+		z.NegEqual()
+	}
+	return z
+}
+
+// Reduce function returns x with numerator and denominator divided by
+// their greatest common divisor, as 1/2 for 5/10. Zero is returned as 0/1.
+func Reduce(x *Numeric) *Numeric {
+	switch {
+	case x.big != nil:
+		// big.Rat is always reduced
+		return Copy(x)
+	case x.IsZero():
+		return &Numeric{num: 0, den: 1}
+	}
+	g := gcd(x.num, x.den)
+	return &Numeric{num: x.num / g, den: x.den / g}
+}
+
+// MulEqual function: z.MulEqual(x) -> z *= x
+func (n *Numeric) MulEqual(x *Numeric) {
+	if n.IsZero() || x.IsZero() {
+		*n = Numeric{}
+		return
+	}
+	if n.big == nil && x.big == nil {
+		num, ok1 := mul(n.num, x.num)
+		den, ok2 := mul(n.den, x.den)
+		if ok1 && ok2 {
+			n.num, n.den = num, den
+			return
+		}
+	}
+	n.setRat(new(big.Rat).Mul(n.rat(), x.rat()))
+}
+
+// DivEqual function: z.DivEqual(x) -> z /= x
+//
+// It panics if x is zero.
+func (n *Numeric) DivEqual(x *Numeric) {
+	if x.IsZero() {
+		panic("types: division by zero")
+	}
+	if n.IsZero() {
+		*n = Numeric{}
+		return
+	}
+	if n.big == nil && x.big == nil {
+		num, ok1 := mul(n.num, x.den)
+		den, ok2 := mul(n.den, x.num)
+		if ok1 && ok2 && num != math.MinInt64 && den != math.MinInt64 {
+			if den < 0 {
+				num, den = -num, -den
+			}
+			n.num, n.den = num, den
+			return
+		}
+	}
+	n.setRat(new(big.Rat).Quo(n.rat(), x.rat()))
+}
+
+// Mul function returns x*y.
+func Mul(x *Numeric, y *Numeric) *Numeric {
+	z := Copy(x)
+	z.MulEqual(y)
+	return z
+}
+
+// Div function returns x/y.
+//
+// It panics if y is zero.
+func Div(x *Numeric, y *Numeric) *Numeric {
+	z := Copy(x)
+	z.DivEqual(y)
+	return z
 }
 
 // Float64 converts the Numeric to a float64 value.
@@ -175,6 +385,10 @@ func (n *Numeric) Float64() float64 {
 	if n.IsZero() {
 		return 0.0
 	}
+	if n.big != nil {
+		f, _ := n.big.Float64()
+		return f
+	}
 	return float64(n.num) / float64(n.den)
 }
 
@@ -182,7 +396,9 @@ func (n *Numeric) Float64() float64 {
 func (n *Numeric) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	// http://stackoverflow.com/questions/17301149/golang-xml-unmarshal-and-time-time-fields
 	var v string
-	d.DecodeElement(&v, &start)
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
 	x, err := FromString(v)
 	if err != nil {
 		return err
@@ -1,6 +1,11 @@
 package types
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
 
 func TestNew(t *testing.T) {
 	const N numint = 3
@@ -18,6 +23,23 @@ func TestNew(t *testing.T) {
 		}
 	}
 }
+func TestFromInt64(t *testing.T) {
+	var testCases = []struct {
+		num, den int64
+		expected string
+	}{
+		{12345, 100, "12345/100"},
+		{-5, 1, "-5/1"},
+		{5, -2, "-5/2"},
+		{0, 0, "0/1"},
+	}
+	for _, tc := range testCases {
+		if actual := FromInt64(tc.num, tc.den).GncString(); actual != tc.expected {
+			t.Errorf("FromInt64(%d, %d): expected %q, got %q", tc.num, tc.den, tc.expected, actual)
+		}
+	}
+}
+
 func TestIsZero(t *testing.T) {
 	var testCases = []struct {
 		num, den numint
@@ -56,6 +78,28 @@ func TestEqual(t *testing.T) {
 	}
 }
 
+func TestSign(t *testing.T) {
+	var testCases = []struct {
+		num, den numint
+		expected int
+	}{
+		{0, 1, 0},
+		{0, 100, 0},
+		{5, 0, 0},
+		{1, 100, 1},
+		{-1, 100, -1},
+		{1, -100, -1},
+	}
+
+	for _, tc := range testCases {
+		z := New(tc.num, tc.den)
+		actual := z.Sign()
+		if actual != tc.expected {
+			t.Errorf("Sign: num=%d, den=%d, expected %d, got %d", tc.num, tc.den, tc.expected, actual)
+		}
+	}
+}
+
 func TestString(t *testing.T) {
 	var testCases = []struct {
 		num, den numint
@@ -176,3 +220,260 @@ func TestSub(t *testing.T) {
 		}
 	}
 }
+
+func TestMul(t *testing.T) {
+	var testCases = []struct {
+		a, b     *Numeric
+		expected *Numeric
+	}{
+		{New(150, 100), New(2, 1), New(300, 100)},
+		{New(1, 2), New(1, 3), New(1, 6)},
+		{New(-1, 2), New(1, 3), New(-1, 6)},
+		{New(0, 1), New(5, 10), New(0, 1)},
+	}
+
+	for _, tc := range testCases {
+		actual := Mul(tc.a, tc.b)
+		if !actual.Equals(tc.expected) {
+			t.Errorf("Mul: %s * %s, expected %v, got %v", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	var testCases = []struct {
+		a, b     *Numeric
+		expected *Numeric
+	}{
+		{New(150, 100), New(2, 1), New(150, 200)},
+		{New(1, 2), New(1, 3), New(3, 2)},
+		{New(1, 2), New(-1, 3), New(-3, 2)},
+		{New(0, 1), New(5, 10), New(0, 1)},
+	}
+
+	for _, tc := range testCases {
+		actual := Div(tc.a, tc.b)
+		if !actual.Equals(tc.expected) {
+			t.Errorf("Div: %s / %s, expected %v, got %v", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Div: expected panic dividing by zero")
+		}
+	}()
+	Div(New(1, 1), New(0, 1))
+}
+
+func TestGncString(t *testing.T) {
+	var testCases = []struct {
+		n        *Numeric
+		expected string
+	}{
+		{New(150, 100), "150/100"},
+		{New(-3, 1), "-3/1"},
+		{New(0, 100), "0/100"},
+		{&Numeric{}, "0/1"},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.n.GncString(); actual != tc.expected {
+			t.Errorf("GncString: expected %q, got %q", tc.expected, actual)
+		}
+	}
+}
+
+func TestEqualValue(t *testing.T) {
+	var testCases = []struct {
+		a, b     *Numeric
+		expected bool
+	}{
+		{New(1, 1), New(10, 10), true},
+		{New(150, 100), New(3, 2), true},
+		{New(0, 1), New(1, 0), true},
+		{New(1, 2), New(2, 3), false},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.a.Equal(tc.b); actual != tc.expected {
+			t.Errorf("Equal: %s == %s, expected %v, got %v", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestCmp(t *testing.T) {
+	var testCases = []struct {
+		a, b     *Numeric
+		expected int
+	}{
+		{New(1, 2), New(2, 3), -1},
+		{New(2, 3), New(1, 2), 1},
+		{New(5, 10), New(1, 2), 0},
+		{New(-1, 2), New(0, 1), -1},
+		{New(0, 0), New(-1, 3), 1},
+		{New(math.MaxInt64, 3), New(math.MaxInt64-1, 3), 1},
+		{New(math.MaxInt64, math.MaxInt64-1), New(math.MaxInt64-1, math.MaxInt64-2), -1},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.a.Cmp(tc.b); actual != tc.expected {
+			t.Errorf("Cmp: %s <=> %s, expected %d, got %d", tc.a, tc.b, tc.expected, actual)
+		}
+	}
+}
+
+func TestAbsReduce(t *testing.T) {
+	if actual := Abs(New(-150, 100)); actual.GncString() != "150/100" {
+		t.Errorf("Abs: expected 150/100, got %s", actual.GncString())
+	}
+	if actual := Abs(New(3, 1)); actual.GncString() != "3/1" {
+		t.Errorf("Abs: expected 3/1, got %s", actual.GncString())
+	}
+	var testCases = []struct {
+		n        *Numeric
+		expected string
+	}{
+		{New(150, 100), "3/2"},
+		{New(-10, 10), "-1/1"},
+		{New(0, 100), "0/1"},
+		{New(7, 3), "7/3"},
+	}
+	for _, tc := range testCases {
+		if actual := Reduce(tc.n).GncString(); actual != tc.expected {
+			t.Errorf("Reduce(%s): expected %s, got %s", tc.n.GncString(), tc.expected, actual)
+		}
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	// stock quantities with coprime denominators summed over the years
+	sum := New(0, 1)
+	expected := new(big.Rat)
+	for _, den := range []numint{1000003, 1000033, 1000037, 1000039, 999983, 999979} {
+		x := New(123456789, den)
+		sum.AddEqual(x)
+		expected.Add(expected, x.rat())
+	}
+	if sum.big == nil {
+		t.Errorf("expected a big value, got %s", sum.GncString())
+	}
+	if sum.rat().Cmp(expected) != 0 {
+		t.Errorf("AddEqual: expected %s, got %s", expected, sum.GncString())
+	}
+
+	// reduced back to numint
+	for _, den := range []numint{1000003, 1000033, 1000037, 1000039, 999983, 999979} {
+		sum.SubEqual(New(123456789, den))
+	}
+	if !sum.IsZero() || sum.big != nil {
+		t.Errorf("SubEqual: expected 0, got %s", sum.GncString())
+	}
+
+	// written and read back
+	n := Mul(New(math.MaxInt64, 7), New(math.MaxInt64, 11))
+	x, err := FromString(n.GncString())
+	if err != nil || !x.Equal(n) {
+		t.Errorf("FromString(%s): got %v, %v", n.GncString(), x, err)
+	}
+
+	neg := New(math.MinInt64, 1)
+	if Neg(neg).Sign() != 1 || Abs(neg).Sign() != 1 {
+		t.Errorf("Neg(%s): expected positive, got %s", neg, Neg(neg))
+	}
+}
+
+// quickNumeric returns the Numeric num/den, with den not zero.
+func quickNumeric(num, den int64) *Numeric {
+	if den == 0 {
+		den = 1
+	}
+	return FromInt64(num, den)
+}
+
+// quickRat returns the value of quickNumeric as a big.Rat.
+func quickRat(num, den int64) *big.Rat {
+	if den == 0 {
+		den = 1
+	}
+	return new(big.Rat).SetFrac(big.NewInt(num), big.NewInt(den))
+}
+
+func TestQuickArithmetic(t *testing.T) {
+	ops := []struct {
+		name string
+		op   func(x, y *Numeric) *Numeric
+		rat  func(z, x, y *big.Rat) *big.Rat
+	}{
+		{"Add", Add, (*big.Rat).Add},
+		{"Sub", Sub, (*big.Rat).Sub},
+		{"Mul", Mul, (*big.Rat).Mul},
+	}
+	for _, op := range ops {
+		f := func(a, b, c, d int64) bool {
+			z := op.op(quickNumeric(a, b), quickNumeric(c, d))
+			return z.rat().Cmp(op.rat(new(big.Rat), quickRat(a, b), quickRat(c, d))) == 0
+		}
+		if err := quick.Check(f, nil); err != nil {
+			t.Errorf("%s: %s", op.name, err)
+		}
+	}
+
+	div := func(a, b, c, d int64) bool {
+		if c == 0 {
+			return true
+		}
+		z := Div(quickNumeric(a, b), quickNumeric(c, d))
+		return z.rat().Cmp(new(big.Rat).Quo(quickRat(a, b), quickRat(c, d))) == 0
+	}
+	if err := quick.Check(div, nil); err != nil {
+		t.Errorf("Div: %s", err)
+	}
+}
+
+func TestQuickProperties(t *testing.T) {
+	properties := []struct {
+		name string
+		f    interface{}
+	}{
+		{"x+y == y+x", func(a, b, c, d int64) bool {
+			x, y := quickNumeric(a, b), quickNumeric(c, d)
+			return Add(x, y).Equal(Add(y, x))
+		}},
+		{"x-y+y == x", func(a, b, c, d int64) bool {
+			x, y := quickNumeric(a, b), quickNumeric(c, d)
+			return Add(Sub(x, y), y).Equal(x)
+		}},
+		{"x*y/y == x", func(a, b, c, d int64) bool {
+			x, y := quickNumeric(a, b), quickNumeric(c, d)
+			return y.IsZero() || Div(Mul(x, y), y).Equal(x)
+		}},
+		{"Cmp antisymmetric", func(a, b, c, d int64) bool {
+			x, y := quickNumeric(a, b), quickNumeric(c, d)
+			return x.Cmp(y) == -y.Cmp(x) && x.Cmp(y) == quickRat(a, b).Cmp(quickRat(c, d))
+		}},
+		{"Reduce equal", func(a, b int64) bool {
+			x := quickNumeric(a, b)
+			r := Reduce(x)
+			return r.Equal(x) && (r.big != nil || gcd(r.num, r.den) == 1)
+		}},
+		{"scaled equal", func(a, b int32, k int16) bool {
+			if k == 0 {
+				k = 1
+			}
+			x := quickNumeric(int64(a), int64(b))
+			y := quickNumeric(int64(a)*int64(k), int64(b)*int64(k))
+			return x.Equal(y) && x.Cmp(y) == 0
+		}},
+		{"Abs", func(a, b int64) bool {
+			x := quickNumeric(a, b)
+			z := Abs(x)
+			return z.Sign() >= 0 && (z.Equal(x) || z.Equal(Neg(x)))
+		}},
+	}
+	for _, p := range properties {
+		if err := quick.Check(p.f, nil); err != nil {
+			t.Errorf("%s: %s", p.name, err)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
-import "strconv"
+import (
+	"math"
+	"strconv"
+)
 
 // base type of Numeric
 type numint int64
@@ -46,3 +49,24 @@ func lcm(a, b numint) numint {
 
 	return l
 }
+
+// add returns a+b, and false if the sum overflows.
+func add(a, b numint) (numint, bool) {
+	c := a + b
+	if (a > 0 && b > 0 && c < 0) || (a < 0 && b < 0 && c >= 0) {
+		return c, false
+	}
+	return c, true
+}
+
+// mul returns a*b, and false if the product overflows.
+func mul(a, b numint) (numint, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if c/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return c, false
+	}
+	return c, true
+}
@@ -49,7 +49,9 @@ func (rs ReconciledState) String() string {
 // UnmarshalXML implements xml.Unmarshaler interface
 func (rs *ReconciledState) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
 	var v string
-	d.DecodeElement(&v, &start)
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
 	*rs, err = ReconciledStateFromString(v)
 	return err
 }
@@ -0,0 +1,386 @@
+package types
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+/*
+RecurrenceContent = (
+  attribute version { "1.0.0" },
+  element recurrence:mult { xsd:int },
+  element recurrence:period_type { "once"
+                                 | "day"
+                                 | "week"
+                                 | "month"
+                                 | "end of month"
+                                 | "nth weekday"
+                                 | "last weekday"
+                                 | "year" },
+  element recurrence:start { GDate },
+  element recurrence:weekend_adj { "none"
+                                 | "back"
+                                 | "forward" }?
+)
+*/
+
+// PeriodType enum type
+type PeriodType int
+
+// PeriodType constants
+const (
+	PeriodTypeOnce PeriodType = iota
+	PeriodTypeDay
+	PeriodTypeWeek
+	PeriodTypeMonth
+	PeriodTypeEndOfMonth
+	PeriodTypeNthWeekday
+	PeriodTypeLastWeekday
+	PeriodTypeYear
+)
+
+var periodTypeLabels = []string{"once", "day", "week", "month", "end of month", "nth weekday", "last weekday", "year"}
+
+// PeriodTypeFromString returns the PeriodType of the string v.
+func PeriodTypeFromString(v string) (PeriodType, error) {
+	for j, label := range periodTypeLabels {
+		if label == v {
+			return PeriodType(j), nil
+		}
+	}
+	return PeriodTypeOnce, fmt.Errorf("Invalid PeriodType: %q", v)
+}
+
+func (pt PeriodType) String() string {
+	if pt < 0 || int(pt) >= len(periodTypeLabels) {
+		return ""
+	}
+	return periodTypeLabels[pt]
+}
+
+// WeekendAdjust enum type
+type WeekendAdjust int
+
+// WeekendAdjust constants
+const (
+	WeekendAdjustNone WeekendAdjust = iota
+	WeekendAdjustBack
+	WeekendAdjustForward
+)
+
+var weekendAdjustLabels = []string{"none", "back", "forward"}
+
+// WeekendAdjustFromString returns the WeekendAdjust of the string v.
+func WeekendAdjustFromString(v string) (WeekendAdjust, error) {
+	for j, label := range weekendAdjustLabels {
+		if label == v {
+			return WeekendAdjust(j), nil
+		}
+	}
+	return WeekendAdjustNone, fmt.Errorf("Invalid WeekendAdjust: %q", v)
+}
+
+func (wa WeekendAdjust) String() string {
+	if wa < 0 || int(wa) >= len(weekendAdjustLabels) {
+		return ""
+	}
+	return weekendAdjustLabels[wa]
+}
+
+// Recurrence represents a gnucash recurrence:
+// every Mult periods of type PeriodType, starting from Start.
+type Recurrence struct {
+	Mult          int
+	PeriodType    PeriodType
+	Start         GDate
+	WeekendAdjust WeekendAdjust
+}
+
+// UnmarshalXML implements xml.Unmarshaler interface
+func (r *Recurrence) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v struct {
+		Mult          int    `xml:"mult"`
+		PeriodType    string `xml:"period_type"`
+		Start         GDate  `xml:"start"`
+		WeekendAdjust string `xml:"weekend_adj"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	pt, err := PeriodTypeFromString(v.PeriodType)
+	if err != nil {
+		return err
+	}
+	wa := WeekendAdjustNone
+	if v.WeekendAdjust != "" {
+		if wa, err = WeekendAdjustFromString(v.WeekendAdjust); err != nil {
+			return err
+		}
+	}
+	*r = Recurrence{
+		Mult:          v.Mult,
+		PeriodType:    pt,
+		Start:         v.Start,
+		WeekendAdjust: wa,
+	}
+
+	return nil
+}
+
+// String returns a description of the recurrence.
+func (r *Recurrence) String() string {
+	return fmt.Sprintf("every %d %s from %s (weekend adjust %s)", r.Mult, r.PeriodType, r.Start, r.WeekendAdjust)
+}
+
+// Recurrences type is a list of Recurrence,
+// as the schedule of a scheduled transaction.
+type Recurrences []*Recurrence
+
+// Next returns the first occurrence of the recurrence strictly after
+// the date of after. The time of day of after is ignored, and the
+// returned time is midnight UTC. The zero time is returned if there is
+// no next occurrence, as for a PeriodTypeOnce recurrence already occurred.
+//
+// The algorithm reproduces recurrenceNextInstance of GnuCash
+// (src/engine/Recurrence.c), including the clamping of the day to the end
+// of month and the weekend adjustment of monthly and yearly recurrences.
+func (r *Recurrence) Next(after time.Time) time.Time {
+	start := dateOf(time.Time(r.Start))
+	ref := dateOf(after)
+	pt := r.PeriodType
+	wadj := r.WeekendAdjust
+	mult := r.Mult
+	if mult < 1 {
+		mult = 1
+	}
+
+	// The start date itself may fall on a weekend.
+	adjustedStart := start
+	if pt == PeriodTypeMonth || pt == PeriodTypeEndOfMonth || pt == PeriodTypeYear {
+		adjustedStart = adjustWeekend(start, wadj)
+	}
+	// If the ref date comes before the start date then the next
+	// occurrence is always the start date.
+	if ref.Before(adjustedStart) {
+		return adjustedStart
+	}
+	next := ref
+
+	// Step 1: move forward one period, passing exactly one occurrence.
+	switch pt {
+	case PeriodTypeYear, PeriodTypeMonth, PeriodTypeNthWeekday, PeriodTypeLastWeekday, PeriodTypeEndOfMonth:
+		if pt == PeriodTypeYear {
+			mult *= 12
+		}
+		backAdjusted := wadj == WeekendAdjustBack &&
+			(pt == PeriodTypeYear || pt == PeriodTypeMonth || pt == PeriodTypeEndOfMonth)
+		if backAdjusted {
+			// Move a weekend ref back to Friday, so that the following
+			// calculations proceed if ref is between the adjusted
+			// occurrence and the target day.
+			switch next.Weekday() {
+			case time.Saturday:
+				next = next.AddDate(0, 0, -1)
+			case time.Sunday:
+				next = next.AddDate(0, 0, -2)
+			}
+		}
+		if backAdjusted && next.Weekday() == time.Friday {
+			// Go back to the "un-adjusted" date.
+			sat := next.AddDate(0, 0, 1)
+			sun := next.AddDate(0, 0, 2)
+			if pt == PeriodTypeEndOfMonth {
+				if isLastOfMonth(next) || isLastOfMonth(sat) || isLastOfMonth(sun) {
+					next = addMonths(next, mult)
+				} else {
+					next = addMonths(next, mult-1)
+				}
+			} else {
+				switch {
+				case sat.Day() == start.Day():
+					next = addMonths(sat, mult)
+				case sun.Day() == start.Day():
+					next = addMonths(sun, mult)
+				case next.Day() >= start.Day(), isLastOfMonth(next):
+					next = addMonths(next, mult)
+				case isLastOfMonth(sat):
+					next = addMonths(sat, mult)
+				case isLastOfMonth(sun):
+					next = addMonths(sun, mult)
+				default:
+					next = addMonths(next, mult-1)
+				}
+			}
+		} else if isLastOfMonth(next) ||
+			((pt == PeriodTypeMonth || pt == PeriodTypeYear) && next.Day() >= start.Day()) ||
+			((pt == PeriodTypeNthWeekday || pt == PeriodTypeLastWeekday) && nthWeekdayCompare(start, next, pt) <= 0) {
+			next = addMonths(next, mult)
+		} else {
+			// one fewer month forward because of the occurrence in this month
+			next = addMonths(next, mult-1)
+		}
+	case PeriodTypeWeek, PeriodTypeDay:
+		if pt == PeriodTypeWeek {
+			mult *= 7
+		}
+		next = next.AddDate(0, 0, mult)
+	default:
+		// PeriodTypeOnce: the case where ref is earlier than start
+		// has been already caught.
+		return time.Time{}
+	}
+
+	// Step 2: back up to align to the base phase. To ensure forward
+	// progress, we never subtract as much as we added.
+	switch pt {
+	case PeriodTypeWeek, PeriodTypeDay:
+		days := int(next.Sub(start).Hours() / 24)
+		next = next.AddDate(0, 0, -(days % mult))
+	default:
+		months := 12*(next.Year()-start.Year()) + int(next.Month()) - int(start.Month())
+		next = addMonths(next, -(months % mult))
+
+		// Now we are in the right month: align the day.
+		dim := daysIn(next.Month(), next.Year())
+		switch {
+		case pt == PeriodTypeNthWeekday || pt == PeriodTypeLastWeekday:
+			next = next.AddDate(0, 0, nthWeekdayCompare(start, next, pt))
+		case pt == PeriodTypeEndOfMonth || start.Day() >= dim:
+			next = setDay(next, dim)
+		default:
+			next = setDay(next, start.Day())
+		}
+
+		if pt == PeriodTypeYear || pt == PeriodTypeMonth || pt == PeriodTypeEndOfMonth {
+			next = adjustWeekend(next, wadj)
+		}
+	}
+
+	return next
+}
+
+// Occurrences returns the occurrences of the recurrence
+// between the dates of from and to, both included.
+func (r *Recurrence) Occurrences(from, to time.Time) []time.Time {
+	return occurrences(r.Next, from, to)
+}
+
+// Next returns the earliest of the next occurrences of the recurrences,
+// or the zero time if no recurrence has a next occurrence.
+func (rs Recurrences) Next(after time.Time) time.Time {
+	var next time.Time
+	for _, r := range rs {
+		d := r.Next(after)
+		if !d.IsZero() && (next.IsZero() || d.Before(next)) {
+			next = d
+		}
+	}
+	return next
+}
+
+// Occurrences returns the occurrences of any of the recurrences
+// between the dates of from and to, both included.
+func (rs Recurrences) Occurrences(from, to time.Time) []time.Time {
+	return occurrences(rs.Next, from, to)
+}
+
+// occurrences iterates the next function from the day before from,
+// returning the dates not after to.
+func occurrences(next func(time.Time) time.Time, from, to time.Time) []time.Time {
+	var list []time.Time
+
+	to = dateOf(to)
+	d := next(dateOf(from).AddDate(0, 0, -1))
+	for !d.IsZero() && !d.After(to) {
+		list = append(list, d)
+		n := next(d)
+		if !n.After(d) {
+			// no progress: should never happen
+			break
+		}
+		d = n
+	}
+	return list
+}
+
+// nthWeekdayCompare returns the number of days from next to the
+// occurrence, in the month of next, of the weekday of start:
+// positive if next is before the match.
+func nthWeekdayCompare(start, next time.Time, pt PeriodType) int {
+	nd := next.Day()
+	sd := start.Day()
+
+	// matchday has a week part, capped at 3 weeks, and a day part,
+	// capped at 7 days, so max(matchday) == 3*7 + 7 == 28.
+	week := (sd - 1) / 7
+	if week == 4 {
+		week = 3
+	}
+	matchday := 7*week + (nd-int(next.Weekday())+int(start.Weekday())+7)%7
+
+	dim := daysIn(next.Month(), next.Year())
+	if dim-matchday >= 7 && pt == PeriodTypeLastWeekday {
+		// go to the fifth week, if needed
+		matchday += 7
+	}
+	if pt == PeriodTypeNthWeekday && matchday%7 == 0 {
+		matchday += 7
+	}
+
+	return matchday - nd
+}
+
+// adjustWeekend moves a date falling on a weekend
+// according to the weekend adjustment.
+func adjustWeekend(d time.Time, wadj WeekendAdjust) time.Time {
+	switch d.Weekday() {
+	case time.Saturday:
+		switch wadj {
+		case WeekendAdjustBack:
+			return d.AddDate(0, 0, -1)
+		case WeekendAdjustForward:
+			return d.AddDate(0, 0, 2)
+		}
+	case time.Sunday:
+		switch wadj {
+		case WeekendAdjustBack:
+			return d.AddDate(0, 0, -2)
+		case WeekendAdjustForward:
+			return d.AddDate(0, 0, 1)
+		}
+	}
+	return d
+}
+
+// dateOf returns the date of t at midnight UTC.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// daysIn returns the number of days of the month.
+func daysIn(m time.Month, year int) int {
+	return time.Date(year, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// isLastOfMonth returns true if d is the last day of its month.
+func isLastOfMonth(d time.Time) bool {
+	return d.Day() == daysIn(d.Month(), d.Year())
+}
+
+// addMonths adds n months to d, clamping the day to the end of
+// the resulting month, like g_date_add_months.
+func addMonths(d time.Time, n int) time.Time {
+	first := time.Date(d.Year(), d.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+	return setDay(first, d.Day())
+}
+
+// setDay sets the day of d, clamping it to the end of the month.
+func setDay(d time.Time, day int) time.Time {
+	if dim := daysIn(d.Month(), d.Year()); day > dim {
+		day = dim
+	}
+	return time.Date(d.Year(), d.Month(), day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,217 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	if s == "" {
+		return time.Time{}
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func fmtDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	var testCases = []struct {
+		mult     int
+		pt       PeriodType
+		start    string
+		wadj     WeekendAdjust
+		after    string
+		expected string
+	}{
+		// once
+		{1, PeriodTypeOnce, "2016-03-01", WeekendAdjustNone, "2016-01-01", "2016-03-01"},
+		{1, PeriodTypeOnce, "2016-03-01", WeekendAdjustNone, "2016-03-01", ""},
+		// day
+		{1, PeriodTypeDay, "2016-01-01", WeekendAdjustNone, "2016-01-01", "2016-01-02"},
+		{3, PeriodTypeDay, "2016-01-01", WeekendAdjustNone, "2016-01-05", "2016-01-07"},
+		{3, PeriodTypeDay, "2016-01-01", WeekendAdjustNone, "2016-01-07", "2016-01-10"},
+		// week
+		{2, PeriodTypeWeek, "2016-01-04", WeekendAdjustNone, "2016-01-04", "2016-01-18"},
+		{2, PeriodTypeWeek, "2016-01-04", WeekendAdjustNone, "2016-01-10", "2016-01-18"},
+		{1, PeriodTypeWeek, "2016-01-04", WeekendAdjustNone, "2015-12-01", "2016-01-04"},
+		// month, with end of month clamping
+		{1, PeriodTypeMonth, "2016-01-31", WeekendAdjustNone, "2016-01-31", "2016-02-29"},
+		{1, PeriodTypeMonth, "2016-01-31", WeekendAdjustNone, "2016-02-29", "2016-03-31"},
+		{1, PeriodTypeMonth, "2016-01-31", WeekendAdjustNone, "2016-03-31", "2016-04-30"},
+		{1, PeriodTypeMonth, "2016-01-15", WeekendAdjustNone, "2016-03-10", "2016-03-15"},
+		{3, PeriodTypeMonth, "2016-01-15", WeekendAdjustNone, "2016-01-15", "2016-04-15"},
+		{3, PeriodTypeMonth, "2016-01-15", WeekendAdjustNone, "2016-02-20", "2016-04-15"},
+		// month, with weekend adjustment
+		{1, PeriodTypeMonth, "2016-01-15", WeekendAdjustNone, "2016-09-15", "2016-10-15"},
+		{1, PeriodTypeMonth, "2016-01-15", WeekendAdjustForward, "2016-09-15", "2016-10-17"},
+		{1, PeriodTypeMonth, "2016-01-15", WeekendAdjustForward, "2016-10-17", "2016-11-15"},
+		{1, PeriodTypeMonth, "2016-01-15", WeekendAdjustBack, "2016-09-15", "2016-10-14"},
+		{1, PeriodTypeMonth, "2016-01-15", WeekendAdjustBack, "2016-10-14", "2016-11-15"},
+		{1, PeriodTypeMonth, "2016-01-16", WeekendAdjustForward, "2016-01-01", "2016-01-18"},
+		// end of month
+		{1, PeriodTypeEndOfMonth, "2016-01-15", WeekendAdjustNone, "2016-01-01", "2016-01-15"},
+		{1, PeriodTypeEndOfMonth, "2016-01-15", WeekendAdjustNone, "2016-01-15", "2016-01-31"},
+		{1, PeriodTypeEndOfMonth, "2016-01-31", WeekendAdjustNone, "2016-01-31", "2016-02-29"},
+		{1, PeriodTypeEndOfMonth, "2016-01-31", WeekendAdjustBack, "2016-04-15", "2016-04-29"},
+		{1, PeriodTypeEndOfMonth, "2016-01-31", WeekendAdjustBack, "2016-04-29", "2016-05-31"},
+		// nth weekday: 2nd tuesday
+		{1, PeriodTypeNthWeekday, "2016-01-12", WeekendAdjustNone, "2016-01-12", "2016-02-09"},
+		{1, PeriodTypeNthWeekday, "2016-01-12", WeekendAdjustNone, "2016-02-01", "2016-02-09"},
+		{1, PeriodTypeNthWeekday, "2016-01-12", WeekendAdjustNone, "2016-02-09", "2016-03-08"},
+		// last weekday: last tuesday
+		{1, PeriodTypeLastWeekday, "2016-01-26", WeekendAdjustNone, "2016-01-26", "2016-02-23"},
+		{1, PeriodTypeLastWeekday, "2016-01-26", WeekendAdjustNone, "2016-02-23", "2016-03-29"},
+		// year
+		{1, PeriodTypeYear, "2016-02-29", WeekendAdjustNone, "2016-02-29", "2017-02-28"},
+		{1, PeriodTypeYear, "2016-02-29", WeekendAdjustNone, "2019-02-28", "2020-02-29"},
+		{2, PeriodTypeYear, "2016-06-01", WeekendAdjustNone, "2016-07-01", "2018-06-01"},
+		{1, PeriodTypeYear, "2016-06-05", WeekendAdjustBack, "2016-07-01", "2017-06-05"},
+		{1, PeriodTypeYear, "2016-06-04", WeekendAdjustForward, "2016-07-01", "2017-06-05"},
+	}
+
+	for _, tc := range testCases {
+		r := Recurrence{
+			Mult:          tc.mult,
+			PeriodType:    tc.pt,
+			Start:         GDate(date(tc.start)),
+			WeekendAdjust: tc.wadj,
+		}
+		actual := fmtDate(r.Next(date(tc.after)))
+		if actual != tc.expected {
+			t.Errorf("Next(%s) of %s: expected %q, got %q", tc.after, &r, tc.expected, actual)
+		}
+	}
+}
+
+func TestRecurrenceNextWeekend(t *testing.T) {
+	// 2023-04-30 is a sunday, 2023-09-30 a saturday
+	var testCases = []struct {
+		pt       PeriodType
+		start    string
+		wadj     WeekendAdjust
+		after    string
+		expected string
+	}{
+		// month from the 31st
+		{PeriodTypeMonth, "2023-01-31", WeekendAdjustBack, "2023-04-27", "2023-04-28"},
+		{PeriodTypeMonth, "2023-01-31", WeekendAdjustBack, "2023-04-28", "2023-05-31"},
+		{PeriodTypeMonth, "2023-01-31", WeekendAdjustBack, "2023-04-29", "2023-05-31"},
+		{PeriodTypeMonth, "2023-01-31", WeekendAdjustBack, "2023-09-29", "2023-10-31"},
+		{PeriodTypeMonth, "2023-01-31", WeekendAdjustForward, "2023-04-28", "2023-05-01"},
+		{PeriodTypeMonth, "2023-01-31", WeekendAdjustForward, "2023-05-01", "2023-05-31"},
+		// end of month
+		{PeriodTypeEndOfMonth, "2023-01-31", WeekendAdjustBack, "2023-04-28", "2023-05-31"},
+		{PeriodTypeEndOfMonth, "2023-01-31", WeekendAdjustBack, "2023-09-29", "2023-10-31"},
+		{PeriodTypeEndOfMonth, "2023-01-31", WeekendAdjustBack, "2023-09-30", "2023-10-31"},
+		{PeriodTypeEndOfMonth, "2023-01-31", WeekendAdjustBack, "2023-12-29", "2024-01-31"},
+		{PeriodTypeEndOfMonth, "2023-01-31", WeekendAdjustForward, "2023-09-29", "2023-10-02"},
+		{PeriodTypeEndOfMonth, "2023-01-31", WeekendAdjustForward, "2023-10-02", "2023-10-31"},
+		// last weekday: the weekend adjustment does not apply
+		{PeriodTypeLastWeekday, "2023-01-27", WeekendAdjustBack, "2023-03-31", "2023-04-28"},
+		{PeriodTypeLastWeekday, "2023-01-28", WeekendAdjustBack, "2023-04-28", "2023-04-29"},
+		{PeriodTypeLastWeekday, "2023-01-28", WeekendAdjustBack, "2023-04-29", "2023-05-27"},
+		{PeriodTypeLastWeekday, "2023-01-29", WeekendAdjustForward, "2023-04-30", "2023-05-28"},
+		// year
+		{PeriodTypeYear, "2022-04-30", WeekendAdjustBack, "2023-04-28", "2024-04-30"},
+	}
+
+	for _, tc := range testCases {
+		r := Recurrence{
+			Mult:          1,
+			PeriodType:    tc.pt,
+			Start:         GDate(date(tc.start)),
+			WeekendAdjust: tc.wadj,
+		}
+		after := date(tc.after)
+		next := r.Next(after)
+		if !next.After(after) {
+			t.Errorf("Next(%s) of %s: got %s, not after", tc.after, &r, fmtDate(next))
+		}
+		if actual := fmtDate(next); actual != tc.expected {
+			t.Errorf("Next(%s) of %s: expected %q, got %q", tc.after, &r, tc.expected, actual)
+		}
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	var testCases = []struct {
+		r        Recurrence
+		from, to string
+		expected []string
+	}{
+		{
+			Recurrence{1, PeriodTypeMonth, GDate(date("2016-01-31")), WeekendAdjustNone},
+			"2016-01-01", "2016-05-01",
+			[]string{"2016-01-31", "2016-02-29", "2016-03-31", "2016-04-30"},
+		},
+		{
+			Recurrence{1, PeriodTypeWeek, GDate(date("2016-01-04")), WeekendAdjustNone},
+			"2016-01-11", "2016-01-25",
+			[]string{"2016-01-11", "2016-01-18", "2016-01-25"},
+		},
+		{
+			Recurrence{1, PeriodTypeMonth, GDate(date("2016-01-15")), WeekendAdjustBack},
+			"2016-09-01", "2016-12-31",
+			[]string{"2016-09-15", "2016-10-14", "2016-11-15", "2016-12-15"},
+		},
+		{
+			Recurrence{1, PeriodTypeMonth, GDate(date("2023-01-31")), WeekendAdjustBack},
+			"2023-01-01", "2023-12-31",
+			[]string{"2023-01-31", "2023-02-28", "2023-03-31", "2023-04-28", "2023-05-31", "2023-06-30",
+				"2023-07-31", "2023-08-31", "2023-09-29", "2023-10-31", "2023-11-30", "2023-12-29"},
+		},
+		{
+			Recurrence{1, PeriodTypeOnce, GDate(date("2016-01-15")), WeekendAdjustNone},
+			"2016-01-01", "2016-12-31",
+			[]string{"2016-01-15"},
+		},
+	}
+
+	for _, tc := range testCases {
+		list := tc.r.Occurrences(date(tc.from), date(tc.to))
+		actual := make([]string, len(list))
+		for j, d := range list {
+			actual[j] = fmtDate(d)
+		}
+		if len(actual) != len(tc.expected) {
+			t.Errorf("Occurrences of %s: expected %v, got %v", &tc.r, tc.expected, actual)
+			continue
+		}
+		for j := range actual {
+			if actual[j] != tc.expected[j] {
+				t.Errorf("Occurrences of %s: expected %v, got %v", &tc.r, tc.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestRecurrencesNext(t *testing.T) {
+	// the 1st and the 15th of every month
+	rs := Recurrences{
+		&Recurrence{1, PeriodTypeMonth, GDate(date("2016-01-01")), WeekendAdjustNone},
+		&Recurrence{1, PeriodTypeMonth, GDate(date("2016-01-15")), WeekendAdjustNone},
+	}
+	var testCases = []struct {
+		after, expected string
+	}{
+		{"2015-12-01", "2016-01-01"},
+		{"2016-01-01", "2016-01-15"},
+		{"2016-01-15", "2016-02-01"},
+	}
+	for _, tc := range testCases {
+		actual := fmtDate(rs.Next(date(tc.after)))
+		if actual != tc.expected {
+			t.Errorf("Recurrences.Next(%s): expected %q, got %q", tc.after, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package types
+
+import "math/big"
+
+// RoundingMode type is the rounding of Numeric.Convert,
+// as the GNC_HOW_RND_* flags of gnc-numeric.h.
+type RoundingMode int
+
+// RoundingMode constants
+const (
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor RoundingMode = iota
+	// RoundCeil rounds toward positive infinity.
+	RoundCeil
+	// RoundTruncate rounds toward zero.
+	RoundTruncate
+	// RoundPromote rounds away from zero.
+	RoundPromote
+	// RoundHalfDown rounds to the nearest, the ties toward zero.
+	RoundHalfDown
+	// RoundHalfUp rounds to the nearest, the ties away from zero.
+	// It is the rounding of the amounts of splits and invoice entries.
+	RoundHalfUp
+	// RoundBankers rounds to the nearest, the ties to the even numerator.
+	RoundBankers
+)
+
+var roundingModeLabels = []string{"floor", "ceil", "truncate", "promote", "half-down", "half-up", "bankers"}
+
+func (m RoundingMode) String() string {
+	if m < 0 || int(m) >= len(roundingModeLabels) {
+		return "unknown"
+	}
+	return roundingModeLabels[m]
+}
+
+// Convert returns z with denominator den, rounding the numerator with
+// mode if z is not a multiple of 1/den, as gnc_numeric_convert does.
+// The result is not reduced: Convert(100) of 3/2 is 150/100.
+//
+// It panics if den is not positive.
+func (n *Numeric) Convert(den int64, mode RoundingMode) *Numeric {
+	if den <= 0 {
+		panic("types: invalid denominator")
+	}
+	if n.IsZero() {
+		return &Numeric{num: 0, den: numint(den)}
+	}
+
+	r := n.rat()
+	// num*den/r.den = q + m/r.den, with q truncated toward zero
+	x := new(big.Int).Mul(r.Num(), big.NewInt(den))
+	q, m := new(big.Int).QuoRem(x, r.Denom(), new(big.Int))
+
+	if m.Sign() != 0 {
+		neg := x.Sign() < 0
+		var away bool
+		switch mode {
+		case RoundFloor:
+			away = neg
+		case RoundCeil:
+			away = !neg
+		case RoundTruncate:
+			away = false
+		case RoundPromote:
+			away = true
+		default:
+			// compare the remainder with the half
+			half := new(big.Int).Abs(m)
+			cmp := half.Lsh(half, 1).Cmp(r.Denom())
+			switch mode {
+			case RoundHalfDown:
+				away = cmp > 0
+			case RoundHalfUp:
+				away = cmp >= 0
+			case RoundBankers:
+				away = cmp > 0 || (cmp == 0 && q.Bit(0) == 1)
+			}
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(x.Sign())))
+		}
+	}
+
+	if q.IsInt64() {
+		return &Numeric{num: numint(q.Int64()), den: numint(den)}
+	}
+	z := &Numeric{}
+	z.setRat(new(big.Rat).SetFrac(q, big.NewInt(den)))
+	return z
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	modes := []RoundingMode{RoundFloor, RoundCeil, RoundTruncate, RoundPromote, RoundHalfDown, RoundHalfUp, RoundBankers}
+	var testCases = []struct {
+		n        *Numeric
+		expected [7]numint // by mode, with denominator 1
+	}{
+		{New(5, 2), [7]numint{2, 3, 2, 3, 2, 3, 2}},
+		{New(7, 2), [7]numint{3, 4, 3, 4, 3, 4, 4}},
+		{New(-5, 2), [7]numint{-3, -2, -2, -3, -2, -3, -2}},
+		{New(-7, 2), [7]numint{-4, -3, -3, -4, -3, -4, -4}},
+		{New(13, 10), [7]numint{1, 2, 1, 2, 1, 1, 1}},
+		{New(-17, 10), [7]numint{-2, -1, -1, -2, -2, -2, -2}},
+		{New(4, 2), [7]numint{2, 2, 2, 2, 2, 2, 2}},
+	}
+
+	for _, tc := range testCases {
+		for j, mode := range modes {
+			actual := tc.n.Convert(1, mode)
+			if actual.num != tc.expected[j] || actual.den != 1 {
+				t.Errorf("Convert(%s, 1, %s): expected %d/1, got %s", tc.n, mode, tc.expected[j], actual.GncString())
+			}
+		}
+	}
+}
+
+func TestConvertDenominator(t *testing.T) {
+	var testCases = []struct {
+		n        *Numeric
+		den      int64
+		mode     RoundingMode
+		expected string
+	}{
+		{New(3, 2), 100, RoundHalfUp, "150/100"},
+		{New(1, 3), 100, RoundHalfUp, "33/100"},
+		{New(2, 3), 100, RoundHalfUp, "67/100"},
+		{New(-2, 3), 100, RoundTruncate, "-66/100"},
+		{New(12345, 1000), 100, RoundBankers, "1234/100"},
+		{New(12355, 1000), 100, RoundBankers, "1236/100"},
+		{New(0, 0), 100, RoundHalfUp, "0/100"},
+		{New(1, 8), 8, RoundFloor, "1/8"},
+		{Mul(New(math.MaxInt64, 7), New(math.MaxInt64, 11)), 1, RoundFloor, "1104812879613436569446713088106915600/1"},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.n.Convert(tc.den, tc.mode).GncString(); actual != tc.expected {
+			t.Errorf("Convert(%s, %d, %s): expected %s, got %s", tc.n.GncString(), tc.den, tc.mode, tc.expected, actual)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Convert: expected panic with denominator 0")
+		}
+	}()
+	New(1, 2).Convert(0, RoundHalfUp)
+}
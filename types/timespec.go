@@ -16,18 +16,22 @@ TimeStamp = element ts:date { xsd:string { pattern = "[0-9]{4}-[0-9]{2}-[0-9]{2}
 // Timespec represent a gnucash Timespec value.
 type Timespec time.Time
 
+// timespecForm is the layout of a Timespec value.
+const timespecForm = "2006-01-02 15:04:05 -0700"
+
 // UnmarshalXML implements xml.Unmarshaler interface
 func (ts *Timespec) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	// http://stackoverflow.com/questions/17301149/golang-xml-unmarshal-and-time-time-fields
-	const gncForm = "2006-01-02 15:04:05 -0700"
 	//var v string
 	var v struct {
 		Date string `xml:"date"`
 		Ns   int    `xml:"ns"`
 	}
-	d.DecodeElement(&v, &start)
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
 
-	x, err := time.Parse(gncForm, v.Date)
+	x, err := time.Parse(timespecForm, v.Date)
 	if err != nil {
 		return err
 	}
@@ -46,3 +50,9 @@ func (ts Timespec) String() string {
 	}
 	return t.String()
 }
+
+// GncString returns the time formatted as in the GnuCash files:
+//	"2006-01-02 15:04:05 -0700"
+func (ts Timespec) GncString() string {
+	return time.Time(ts).Format(timespecForm)
+}